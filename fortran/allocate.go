@@ -0,0 +1,229 @@
+package fortran
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const lboundPostfix string = "_LBOUND"
+
+// lowerBound return lower bound of dimension of allocatable array.
+// Lower bounds are saved at runtime by statement ALLOCATE in Go variable
+// with postfix _LBOUND.
+// Example:
+//  A_LBOUND [ 0 ]
+func (p *parser) lowerBound(name string, col int) []node {
+	v, _ := p.initVars.get(name)
+	p.lbounds[v.name] = true
+	return []node{
+		{tok: token.IDENT, b: []byte(v.name + lboundPostfix)},
+		{tok: token.LBRACK, b: []byte("[")},
+		{tok: token.INT, b: []byte(strconv.Itoa(col))},
+		{tok: token.RBRACK, b: []byte("]")},
+	}
+}
+
+// lowerBounds return declaration of lower bounds of allocatable array.
+// Example:
+//  A_LBOUND := &[2]int{1, 1}
+func lowerBounds(name string, typ goType) goast.Stmt {
+	ones := make([]goast.Expr, len(typ.arrayNode))
+	for i := range ones {
+		ones[i] = goast.NewIdent("1")
+	}
+	return &goast.AssignStmt{
+		Lhs: []goast.Expr{goast.NewIdent(name + lboundPostfix)},
+		Tok: token.DEFINE,
+		Rhs: []goast.Expr{&goast.UnaryExpr{
+			Op: token.AND,
+			X: &goast.CompositeLit{
+				Type: goast.NewIdent(fmt.Sprintf("[%d]int", len(ones))),
+				Elts: ones,
+			},
+		}},
+	}
+}
+
+// setLowerBounds return assignment of lower bounds of allocatable array.
+// Example:
+//  (*A_LBOUND) = [2]int{0, 1}
+func (p *parser) setLowerBounds(name string, lower []goast.Expr) goast.Stmt {
+	v, _ := p.initVars.get(name)
+	p.lbounds[v.name] = true
+	return &goast.AssignStmt{
+		Lhs: []goast.Expr{p.parseExprNodes([]node{
+			{tok: token.IDENT, b: []byte(v.name + lboundPostfix)},
+		})},
+		Tok: token.ASSIGN,
+		Rhs: []goast.Expr{&goast.CompositeLit{
+			Type: goast.NewIdent(fmt.Sprintf("[%d]int", len(lower))),
+			Elts: lower,
+		}},
+	}
+}
+
+// allocateArgs return arrays and STAT of statements ALLOCATE, DEALLOCATE.
+// Example:
+//  ALLOCATE ( A ( N ) , B ( 0 : N , 2 ) , STAT = IERR )
+func (p *parser) allocateArgs() (arrays [][]node, stat []node) {
+	p.ident++
+	args, end := separateArgsParen(p.ns[p.ident:])
+	p.ident += end
+	p.gotoEndLine()
+
+	for _, a := range args {
+		if len(a) > 2 && a[0].tok == token.IDENT && a[1].tok == token.ASSIGN {
+			switch strings.ToUpper(string(a[0].b)) {
+			case "STAT":
+				stat = a[2:]
+			default:
+				p.addError("Not support specifier : " + nodesToString(a))
+			}
+			continue
+		}
+		arrays = append(arrays, a)
+	}
+	return
+}
+
+// statExpr return pointer to STAT variable or nil
+func (p *parser) statExpr(stat []node) goast.Expr {
+	if stat == nil {
+		return goast.NewIdent("nil")
+	}
+	return p.addressOf(stat)
+}
+
+// statChain return statements executed only if previous is successful.
+// Example:
+//  intrinsic.ALLOCATE(IERR, A, (*N))
+//  if (*IERR) == 0 {
+//  	intrinsic.ALLOCATE(IERR, B, (*N)+1, 2)
+//  }
+func (p *parser) statChain(stat []node, calls []goast.Stmt) (stmts []goast.Stmt) {
+	if stat == nil || len(calls) == 0 {
+		return calls
+	}
+	stmts = calls[len(calls)-1:]
+	for i := len(calls) - 2; i >= 0; i-- {
+		stmts = []goast.Stmt{calls[i], &goast.IfStmt{
+			Cond: &goast.BinaryExpr{
+				X:  p.parseExprNodes(stat),
+				Op: token.EQL,
+				Y:  goast.NewIdent("0"),
+			},
+			Body: &goast.BlockStmt{List: stmts},
+		}}
+	}
+	return
+}
+
+// parseAllocate parse statement ALLOCATE.
+// Lower bounds of array are saved at runtime for next use.
+// Example:
+//  ALLOCATE ( A ( N ) , B ( 0 : N , 2 ) , STAT = IERR )
+// Go code:
+//  intrinsic.ALLOCATE(IERR, A, (*N))
+//  if (*IERR) == 0 {
+//  	(*A_LBOUND) = [1]int{1}
+//  	if (*IERR) == 0 {
+//  		intrinsic.ALLOCATE(IERR, B, (*N)-0+1, 2)
+//  		if (*IERR) == 0 {
+//  			(*B_LBOUND) = [2]int{0, 1}
+//  		}
+//  	}
+//  }
+func (p *parser) parseAllocate() (stmts []goast.Stmt) {
+	arrays, stat := p.allocateArgs()
+	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+
+	var calls []goast.Stmt
+	for _, a := range arrays {
+		name := string(a[0].b)
+		v, ok := p.initVars.get(name)
+		if !ok || !v.typ.allocatable {
+			panic(fmt.Errorf("Array %s is not allocatable", name))
+		}
+		if len(a) < 2 || a[1].tok != token.LPAREN {
+			panic(fmt.Errorf("Not valid bounds of array %s", name))
+		}
+		bounds, _ := separateArgsParen(a[1:])
+		if len(bounds) != len(v.typ.arrayNode) {
+			panic(fmt.Errorf("Not valid rank of array %s", name))
+		}
+		call := &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   goast.NewIdent("intrinsic"),
+				Sel: goast.NewIdent("ALLOCATE"),
+			},
+			Args: []goast.Expr{p.statExpr(stat), p.addressOf(a[:1])},
+		}
+		var lower []goast.Expr
+		for _, b := range bounds {
+			l := arrayLoop{lower: []node{nodeOne}, stride: []node{nodeOne}}
+			parts := splitColon(b)
+			l.upper = parts[0]
+			if len(parts) > 1 {
+				l.lower, l.upper = parts[0], parts[1]
+			}
+			call.Args = append(call.Args, p.parseValue(extent(l)))
+			lower = append(lower, p.parseValue(l.lower))
+		}
+		calls = append(calls, &goast.ExprStmt{X: call}, p.setLowerBounds(name, lower))
+	}
+	return p.statChain(stat, calls)
+}
+
+// Example:
+//  DEALLOCATE ( A , B )
+// Go code:
+//  intrinsic.DEALLOCATE(nil, A)
+//  intrinsic.DEALLOCATE(nil, B)
+func (p *parser) parseDeallocate() (stmts []goast.Stmt) {
+	arrays, stat := p.allocateArgs()
+	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+
+	var calls []goast.Stmt
+	for _, a := range arrays {
+		calls = append(calls, &goast.ExprStmt{X: &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   goast.NewIdent("intrinsic"),
+				Sel: goast.NewIdent("DEALLOCATE"),
+			},
+			Args: []goast.Expr{p.statExpr(stat), p.addressOf(a)},
+		}})
+	}
+	return p.statChain(stat, calls)
+}
+
+// parseAllocatable parse statement ALLOCATABLE for declared variables.
+// Examples:
+//  ALLOCATABLE :: A ( : ) , B ( : , : )
+//  ALLOCATABLE A ( : )
+func (p *parser) parseAllocatable() {
+	p.ident++
+	if p.ns[p.ident].tok == ftDoubleColon {
+		p.ident++
+	}
+	start := p.ident
+	p.gotoEndLine()
+	decl := append([]node{nodeLParen}, p.ns[start:p.ident]...)
+	names, _ := separateArgsParen(append(decl, nodeRParen))
+	for _, n := range names {
+		name := string(n[0].b)
+		v, ok := p.initVars.get(name)
+		if !ok {
+			p.addError("Cannot find type of allocatable variable : " + name)
+			continue
+		}
+		if len(n) > 1 {
+			dims, _ := separateArgsParen(n[1:])
+			v.typ.arrayNode = dims
+		}
+		v.typ.allocatable = true
+		p.initVars.set(name, v.typ)
+	}
+}
@@ -0,0 +1,1007 @@
+package fortran
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// elementalFunction is list of intrinsic functions applied elementwise
+// for array arguments
+var elementalFunction = map[string]bool{
+	"ABS": true, "IABS": true, "DABS": true, "CABS": true,
+	"SQRT": true, "DSQRT": true, "CSQRT": true,
+	"EXP": true, "DEXP": true, "LOG": true, "ALOG": true, "DLOG": true,
+	"LOG10": true, "ALOG10": true, "DLOG10": true,
+	"SIN": true, "DSIN": true, "COS": true, "DCOS": true,
+	"TAN": true, "DTAN": true, "ASIN": true, "ACOS": true,
+	"ATAN": true, "ATAN2": true, "SINH": true, "COSH": true, "TANH": true,
+	"MIN": true, "MIN0": true, "AMIN1": true, "DMIN1": true,
+	"MAX": true, "MAX0": true, "AMAX1": true, "DMAX1": true,
+	"MOD": true, "AMOD": true, "DMOD": true,
+	"SIGN": true, "ISIGN": true, "DSIGN": true,
+	"INT": true, "IDINT": true, "NINT": true, "IDNINT": true,
+	"AINT": true, "ANINT": true, "FLOOR": true, "CEILING": true,
+	"REAL": true, "FLOAT": true, "SNGL": true, "DBLE": true,
+	"AIMAG": true, "CONJG": true, "DCONJG": true,
+	"CMPLX": true, "COMPLEX": true, "MERGE": true,
+	"LGE": true, "LGT": true, "LLE": true, "LLT": true,
+}
+
+// isElemental return true for elemental intrinsic function or
+// function with prefix ELEMENTAL
+func (p *parser) isElemental(name string) bool {
+	if elementalFunction[strings.ToUpper(name)] {
+		return true
+	}
+	sig, ok := p.signatureOf(name)
+	return ok && sig.elemental
+}
+
+// arraySection is subscript of one dimension of array.
+// For scalar subscript used only lower value.
+type arraySection struct {
+	isSection            bool
+	lower, upper, stride []node
+}
+
+// arrayLoop is loop over one dimension of array expression
+type arrayLoop struct {
+	index                string // Go-local index of loop
+	lower, upper, stride []node
+}
+
+var (
+	nodeOne    = node{tok: token.INT, b: []byte("1")}
+	nodeLParen = node{tok: token.LPAREN, b: []byte("(")}
+	nodeRParen = node{tok: token.RPAREN, b: []byte(")")}
+	nodeComma  = node{tok: token.COMMA, b: []byte(",")}
+)
+
+// paren return nodes in parens, if that is not single node
+func paren(nodes []node) (out []node) {
+	if len(nodes) == 1 {
+		return nodes
+	}
+	out = append(out, nodeLParen)
+	out = append(out, nodes...)
+	out = append(out, nodeRParen)
+	return
+}
+
+// splitColon separate nodes by colon outside of parens.
+// Example:
+//  2 : N : 2
+func splitColon(nodes []node) (parts [][]node) {
+	parts = append(parts, []node{})
+	counter := 0
+	for _, n := range nodes {
+		switch n.tok {
+		case token.LPAREN:
+			counter++
+		case token.RPAREN:
+			counter--
+		case token.COLON:
+			if counter == 0 {
+				parts = append(parts, []node{})
+				continue
+			}
+		}
+		parts[len(parts)-1] = append(parts[len(parts)-1], n)
+	}
+	return
+}
+
+// isSection return true if one of subscripts is section
+func isSection(args [][]node) bool {
+	for _, a := range args {
+		if len(splitColon(a)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumericArray return true for arrays used in array expressions.
+// Arrays of characters are not acceptable.
+func (p *parser) isNumericArray(name string) bool {
+	v, ok := p.initVars.get(name)
+	return ok && v.typ.isArray() &&
+		v.typ.baseType != "byte" && v.typ.baseType != "string"
+}
+
+// arrayBounds return lower and upper bounds of array dimension.
+// Bounds of allocatable array are calculated by lower bound saved by
+// ALLOCATE and size of array:
+//  ( A_LBOUND [ 0 ] ) + SIZE ( A , 1 ) - 1
+func (p *parser) arrayBounds(name string, col int) (lower, upper []node) {
+	v, _ := p.initVars.get(name)
+	if col >= len(v.typ.arrayNode) {
+		panic(fmt.Errorf("Not enough dimensions of array %s", name))
+	}
+	parts := splitColon(v.typ.arrayNode[col])
+	lower, upper = []node{nodeOne}, parts[0]
+	if len(parts) > 1 {
+		if len(parts[0]) > 0 {
+			lower = parts[0]
+		}
+		upper = parts[1]
+	}
+	if v.typ.allocatable {
+		lower = p.lowerBound(name, col)
+		upper = append(paren(lower), node{tok: token.ADD, b: []byte("+")},
+			node{tok: token.IDENT, b: []byte("SIZE")},
+			nodeLParen,
+			node{tok: token.IDENT, b: []byte(v.name)},
+			nodeComma,
+			node{tok: token.INT, b: []byte(strconv.Itoa(col + 1))},
+			nodeRParen,
+			node{tok: token.SUB, b: []byte("-")}, nodeOne,
+		)
+		return
+	}
+	if len(upper) == 1 && upper[0].tok == token.MUL {
+		panic(fmt.Errorf("Upper bound of assumed-size array %s is unknown", name))
+	}
+	return
+}
+
+// arraySections return subscripts of array for all dimensions.
+// Without subscripts, that is whole array.
+// Examples:
+//  A
+//  A ( 2 : N : 2 )
+//  A ( : , J )
+func (p *parser) arraySections(name string, args [][]node) (sections []arraySection) {
+	if args == nil {
+		for col := 0; col < p.getArrayLen(name); col++ {
+			lower, upper := p.arrayBounds(name, col)
+			sections = append(sections, arraySection{
+				isSection: true,
+				lower:     lower,
+				upper:     upper,
+				stride:    []node{nodeOne},
+			})
+		}
+		return
+	}
+	for col, a := range args {
+		parts := splitColon(a)
+		if len(parts) == 1 {
+			sections = append(sections, arraySection{lower: a})
+			continue
+		}
+		s := arraySection{
+			isSection: true,
+			lower:     parts[0],
+			upper:     parts[1],
+			stride:    []node{nodeOne},
+		}
+		if len(s.lower) == 0 || len(s.upper) == 0 {
+			lower, upper := p.arrayBounds(name, col)
+			if len(s.lower) == 0 {
+				s.lower = lower
+			}
+			if len(s.upper) == 0 {
+				s.upper = upper
+			}
+		}
+		if len(parts) > 2 {
+			s.stride = parts[2]
+		}
+		sections = append(sections, s)
+	}
+	return
+}
+
+// arrayLoops return loops for all sections of array
+func (p *parser) arrayLoops(name string, args [][]node) (loops []arrayLoop) {
+	for _, s := range p.arraySections(name, args) {
+		if !s.isSection {
+			continue
+		}
+		index := fmt.Sprintf("i%d", len(loops)+1)
+		p.arrayIndexes[index] = true
+		loops = append(loops, arrayLoop{
+			index:  index,
+			lower:  s.lower,
+			upper:  s.upper,
+			stride: s.stride,
+		})
+	}
+	return
+}
+
+// sectionIndex return subscript of section for index of loop.
+// Example of section `2 : N` for loop from 1 :
+//  2 + ( i1 - 1 )
+func sectionIndex(s arraySection, l arrayLoop) (out []node) {
+	index := []node{{tok: token.IDENT, b: []byte(l.index)}}
+	if nodesToString(s.lower) == nodesToString(l.lower) &&
+		nodesToString(s.stride) == nodesToString(l.stride) {
+		return index
+	}
+	if nodesToString(s.stride) == "1" && nodesToString(l.stride) == "1" {
+		// Example:
+		//  i1 + 1
+		sl, errS := strconv.Atoi(strings.Replace(nodesToString(s.lower), " ", "", -1))
+		ll, errL := strconv.Atoi(strings.Replace(nodesToString(l.lower), " ", "", -1))
+		if errS == nil && errL == nil {
+			switch {
+			case sl > ll:
+				index = append(index, node{tok: token.ADD, b: []byte("+")},
+					node{tok: token.INT, b: []byte(strconv.Itoa(sl - ll))})
+			case sl < ll:
+				index = append(index, node{tok: token.SUB, b: []byte("-")},
+					node{tok: token.INT, b: []byte(strconv.Itoa(ll - sl))})
+			}
+			return index
+		}
+	}
+	// number of element in section
+	offset := append(index, node{tok: token.SUB, b: []byte("-")})
+	offset = paren(append(offset, paren(l.lower)...))
+	if nodesToString(l.stride) != "1" {
+		offset = append(offset, node{tok: token.QUO, b: []byte("/")})
+		offset = paren(append(offset, paren(l.stride)...))
+	}
+	if nodesToString(s.stride) != "1" {
+		offset = append(append(paren(s.stride),
+			node{tok: token.MUL, b: []byte("*")}), offset...)
+	}
+	out = append(out, paren(s.lower)...)
+	out = append(out, node{tok: token.ADD, b: []byte("+")})
+	out = append(out, offset...)
+	return
+}
+
+// elementRef return reference to element of array for indexes of loops
+func (p *parser) elementRef(name node, args [][]node, loops []arrayLoop) (out []node) {
+	sections := p.arraySections(string(name.b), args)
+	amount := 0
+	for _, s := range sections {
+		if s.isSection {
+			amount++
+		}
+	}
+	if amount != len(loops) {
+		panic(fmt.Errorf("Array %s is not conformable. Rank %d, but expected %d",
+			string(name.b), amount, len(loops)))
+	}
+	out = append(out, name, nodeLParen)
+	m := 0
+	for col, s := range sections {
+		if col > 0 {
+			out = append(out, nodeComma)
+		}
+		if !s.isSection {
+			out = append(out, s.lower...)
+			continue
+		}
+		out = append(out, sectionIndex(s, loops[m])...)
+		m++
+	}
+	out = append(out, nodeRParen)
+	return
+}
+
+// elementalNodes return expression for one element of array expression.
+// Array references are changed to elements for indexes of loops.
+// Argument target is element of assigned array and result overlap is
+// true, if expression use other elements of that array.
+//
+// Example for loop i1 from 1 to N:
+//  B + SQRT ( C ( 2 : N + 1 ) )
+// Result:
+//  B ( i1 ) + SQRT ( C ( 2 + ( i1 - 1 ) ) )
+func (p *parser) elementalNodes(nodes []node, loops []arrayLoop, target []node) (
+	out []node, overlap bool) {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.tok != token.IDENT {
+			out = append(out, n)
+			continue
+		}
+		name := string(n.b)
+		end := i + 1
+		var args [][]node
+		if end < len(nodes) && nodes[end].tok == token.LPAREN {
+			var e int
+			args, e = separateArgsParen(nodes[end:])
+			end += e
+		}
+		isTarget := len(target) > 0 &&
+			strings.ToUpper(name) == strings.ToUpper(string(target[0].b))
+
+		switch {
+		case p.isNumericArray(name) && (args == nil || isSection(args)):
+			// whole array or section
+			ref := p.elementRef(n, args, loops)
+			if isTarget && nodesToString(ref) != nodesToString(target) {
+				overlap = true
+			}
+			out = append(out, ref...)
+
+		case args != nil && !p.isArrayVariable(name) &&
+			p.isElemental(name):
+			// elemental function
+			out = append(out, n, nodeLParen)
+			for j, a := range args {
+				if j > 0 {
+					out = append(out, nodeComma)
+				}
+				arg, o := p.elementalNodes(a, loops, target)
+				overlap = overlap || o
+				out = append(out, arg...)
+			}
+			out = append(out, nodeRParen)
+
+		default:
+			// scalar, element of array or function
+			for j := i; j < end; j++ {
+				if len(target) > 0 && nodes[j].tok == token.IDENT &&
+					strings.ToUpper(string(nodes[j].b)) ==
+						strings.ToUpper(string(target[0].b)) {
+					overlap = true
+				}
+			}
+			out = append(out, nodes[i:end]...)
+		}
+		i = end - 1
+	}
+	return
+}
+
+// exprLoops return loops for shape of array expression by first array
+// in expression. For scalar expression result is nil.
+func (p *parser) exprLoops(nodes []node) (loops []arrayLoop) {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.tok != token.IDENT {
+			continue
+		}
+		var args [][]node
+		end := i + 1
+		if end < len(nodes) && nodes[end].tok == token.LPAREN {
+			var e int
+			args, e = separateArgsParen(nodes[end:])
+			end += e
+		}
+		switch {
+		case p.isNumericArray(string(n.b)) && (args == nil || isSection(args)):
+			return p.arrayLoops(string(n.b), args)
+		case args != nil && !p.isArrayVariable(string(n.b)) &&
+			p.isElemental(string(n.b)):
+			for _, a := range args {
+				if loops = p.exprLoops(a); loops != nil {
+					return
+				}
+			}
+		}
+		i = end - 1
+	}
+	return
+}
+
+// isArrayAssign return true for assignment to whole array or section.
+// Examples:
+//  A = B + C
+//  A ( 2 : N : 2 ) = 0
+func (p *parser) isArrayAssign(lhs []node) bool {
+	if len(lhs) == 0 || lhs[0].tok != token.IDENT ||
+		!p.isNumericArray(string(lhs[0].b)) {
+		return false
+	}
+	if len(lhs) == 1 {
+		return true
+	}
+	args, end := separateArgsParen(lhs[1:])
+	return end+1 == len(lhs) && isSection(args)
+}
+
+// parseArrayAssign return loops for assignment of array expression.
+// Example:
+//  A ( 2 : N ) = B ( 1 : N - 1 ) * 2
+// Go code:
+//  for i1 := 2; i1 <= (*N); i1++ {
+//  	(*A)[i1-(1)] = (*B)[1+(i1-2)-(1)] * 2
+//  }
+//
+// If right part use other elements of array from left part, then
+// elements are calculated before assignment:
+//  {
+//  	var buffer []float64
+//  	for i1 := ... {
+//  		buffer = append(buffer, ...)
+//  	}
+//  	for i1 := ... {
+//  		(*A)[i1-(1)] = buffer[0]
+//  		buffer = buffer[1:]
+//  	}
+//  }
+//
+// Allocatable array is allocated again for shape of right part with
+// lower bounds 1, if shape of array is not same:
+//  if intrinsic.REALLOCATE(A, 5) {
+//  	(*A_LBOUND) = [1]int{1}
+//  }
+func (p *parser) parseArrayAssign(lhs, rhs []node) (stmts []goast.Stmt) {
+	name := string(lhs[0].b)
+	v, _ := p.initVars.get(name)
+	var args [][]node
+	if len(lhs) > 1 {
+		args, _ = separateArgsParen(lhs[1:])
+	}
+	loops := p.arrayLoops(name, args)
+
+	var realloc goast.Stmt
+	if v.typ.allocatable && args == nil {
+		if l := p.exprLoops(rhs); l != nil {
+			loops = l
+			call := &goast.CallExpr{
+				Fun: &goast.SelectorExpr{
+					X:   goast.NewIdent("intrinsic"),
+					Sel: goast.NewIdent("REALLOCATE"),
+				},
+				Args: []goast.Expr{p.addressOf(lhs)},
+			}
+			var lower []goast.Expr
+			for _, l := range loops {
+				call.Args = append(call.Args, p.parseValue(extent(l)))
+				lower = append(lower, goast.NewIdent("1"))
+			}
+			p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+			realloc = &goast.IfStmt{
+				Cond: call,
+				Body: &goast.BlockStmt{List: []goast.Stmt{
+					p.setLowerBounds(name, lower),
+				}},
+			}
+		}
+	}
+
+	target := p.elementRef(lhs[0], args, loops)
+	value, overlap := p.elementalNodes(rhs, loops, target)
+
+	assign := p.assignNodes(target, value)
+	if !overlap {
+		if realloc != nil {
+			stmts = append(stmts, realloc)
+		}
+		stmts = append(stmts, p.arrayFor(loops, []goast.Stmt{assign}))
+		return
+	}
+
+	buffer := goast.NewIdent("buffer")
+	block := &goast.BlockStmt{List: []goast.Stmt{
+		&goast.DeclStmt{Decl: &goast.GenDecl{
+			Tok: token.VAR,
+			Specs: []goast.Spec{&goast.ValueSpec{
+				Names: []*goast.Ident{buffer},
+				Type:  goast.NewIdent("[]" + v.typ.getBaseType()),
+			}},
+		}},
+		p.arrayFor(loops, []goast.Stmt{&goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent("buffer")},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{&goast.CallExpr{
+				Fun:  goast.NewIdent("append"),
+				Args: []goast.Expr{goast.NewIdent("buffer"), assign.Rhs[0]},
+			}},
+		}}),
+	}}
+	if realloc != nil {
+		block.List = append(block.List, realloc)
+	}
+	block.List = append(block.List, p.arrayFor(loops, []goast.Stmt{
+		&goast.AssignStmt{
+			Lhs: assign.Lhs,
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{goast.NewIdent("buffer[0]")},
+		},
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent("buffer")},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{goast.NewIdent("buffer[1:]")},
+		},
+	}))
+	return append(stmts, block)
+}
+
+// addressOf return pointer to variable.
+// Example:
+//  A
+// Go code:
+//  A
+// For element of array:
+//  &(*A)[1]
+func (p *parser) addressOf(nodes []node) goast.Expr {
+	expr := p.parseExprNodes(nodes)
+	if par, ok := expr.(*goast.ParenExpr); ok {
+		if st, ok := par.X.(*goast.StarExpr); ok {
+			return st.X
+		}
+	}
+	return &goast.UnaryExpr{Op: token.AND, X: expr}
+}
+
+// assignNodes return assignment of expressions
+func (p *parser) assignNodes(lhs, rhs []node) *goast.AssignStmt {
+	return &goast.AssignStmt{
+		Lhs: []goast.Expr{p.parseExprNodes(lhs)},
+		Tok: token.ASSIGN,
+		Rhs: []goast.Expr{p.parseValue(rhs)},
+	}
+}
+
+// forLoop return loop with index from lower to upper value with stride.
+// Example:
+//  for (*I) = 1; (*I) <= (*N); (*I)++ {
+//  }
+func (p *parser) forLoop(index []node, tok token.Token,
+	lower, upper, stride []node, body []goast.Stmt) *goast.ForStmt {
+	f := &goast.ForStmt{
+		Init: &goast.AssignStmt{
+			Lhs: []goast.Expr{p.parseExprNodes(index)},
+			Tok: tok,
+			Rhs: []goast.Expr{p.parseValue(lower)},
+		},
+		Body: &goast.BlockStmt{List: body},
+	}
+	cond := func(op token.Token) goast.Expr {
+		return &goast.BinaryExpr{
+			X:  p.parseExprNodes(index),
+			Op: op,
+			Y:  p.parseValue(upper),
+		}
+	}
+	switch {
+	case nodesToString(stride) == "1":
+		f.Cond = cond(token.LEQ)
+		f.Post = &goast.IncDecStmt{X: p.parseExprNodes(index), Tok: token.INC}
+		return f
+	case nodesToString(stride) == "- 1":
+		f.Cond = cond(token.GEQ)
+		f.Post = &goast.IncDecStmt{X: p.parseExprNodes(index), Tok: token.DEC}
+		return f
+	case stride[0].tok == token.SUB:
+		f.Cond = cond(token.GEQ)
+	case len(stride) == 1 && stride[0].tok == token.INT:
+		f.Cond = cond(token.LEQ)
+	default:
+		// sign of stride is unknown
+		sign := func(op token.Token) goast.Expr {
+			return &goast.BinaryExpr{
+				X:  p.parseValue(stride),
+				Op: op,
+				Y:  goast.NewIdent("0"),
+			}
+		}
+		f.Cond = &goast.BinaryExpr{
+			X: &goast.ParenExpr{X: &goast.BinaryExpr{
+				X: sign(token.GTR), Op: token.LAND, Y: cond(token.LEQ),
+			}},
+			Op: token.LOR,
+			Y: &goast.ParenExpr{X: &goast.BinaryExpr{
+				X: sign(token.LSS), Op: token.LAND, Y: cond(token.GEQ),
+			}},
+		}
+	}
+	f.Post = &goast.AssignStmt{
+		Lhs: []goast.Expr{p.parseExprNodes(index)},
+		Tok: token.ADD_ASSIGN,
+		Rhs: []goast.Expr{p.parseValue(stride)},
+	}
+	return f
+}
+
+// arrayFor return loops over dimensions of array expression with body in
+// innermost loop. First dimension is innermost loop as in column-major order
+// of elements.
+//
+// Inside WHERE construct, body is executed only for elements of mask:
+//  {
+//  	k := 0
+//  	for i1 := ... {
+//  		if where[k] == 1 {
+//  			...
+//  		}
+//  		k++
+//  	}
+//  }
+func (p *parser) arrayFor(loops []arrayLoop, body []goast.Stmt) (stmt goast.Stmt) {
+	if p.where > 0 {
+		body = []goast.Stmt{
+			&goast.IfStmt{
+				Cond: &goast.BinaryExpr{
+					X:  goast.NewIdent("where[k]"),
+					Op: token.EQL,
+					Y:  goast.NewIdent(fmt.Sprintf("%d", p.where)),
+				},
+				Body: &goast.BlockStmt{List: body},
+			},
+			&goast.IncDecStmt{X: goast.NewIdent("k"), Tok: token.INC},
+		}
+	}
+	for _, l := range loops {
+		index := []node{{tok: token.IDENT, b: []byte(l.index)}}
+		body = []goast.Stmt{p.forLoop(index, token.DEFINE,
+			l.lower, l.upper, l.stride, body)}
+	}
+	if p.where > 0 {
+		return &goast.BlockStmt{List: append([]goast.Stmt{
+			&goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("k")},
+				Tok: token.DEFINE,
+				Rhs: []goast.Expr{goast.NewIdent("0")},
+			},
+		}, body...)}
+	}
+	return body[0]
+}
+
+// isStatement return true for statement with name and parens.
+// Examples:
+//  WHERE ( A > 0 ) A = 1
+//  FORALL ( I = 1 : N )
+//  ALLOCATE ( A ( N ) )
+func (p *parser) isStatement(name string) bool {
+	if p.ident+1 >= len(p.ns) || p.ns[p.ident].tok != token.IDENT ||
+		strings.ToUpper(string(p.ns[p.ident].b)) != name ||
+		p.ns[p.ident+1].tok != token.LPAREN {
+		return false
+	}
+	// ignore assignment to array with same name
+	_, end := separateArgsParen(p.ns[p.ident+1:])
+	pos := p.ident + 1 + end
+	return pos >= len(p.ns) || p.ns[pos].tok != token.ASSIGN
+}
+
+// isElseWhere return true for statements:
+//  ELSEWHERE ( A > 0 )
+//  ELSE WHERE
+func (p *parser) isElseWhere() bool {
+	n := p.ns[p.ident]
+	if n.tok == token.IDENT && strings.ToUpper(string(n.b)) == "ELSEWHERE" {
+		return true
+	}
+	return n.tok == token.ELSE && p.ident+1 < len(p.ns) &&
+		p.ns[p.ident+1].tok == token.IDENT &&
+		strings.ToUpper(string(p.ns[p.ident+1].b)) == "WHERE"
+}
+
+// Example:
+//  WHERE ( A > 0 )
+//  	B = SQRT ( A )
+//  ELSEWHERE ( A < - 1 )
+//  	B = 1
+//  ELSEWHERE
+//  	B = 0
+//  END WHERE
+//
+// Go code:
+//  {
+//  	where := make([]int, 10)
+//  	k := 0
+//  	for i1 := 1; i1 <= 10; i1++ {
+//  		if where[k] == 0 && (*A)[i1-(1)] > 0 {
+//  			where[k] = 1
+//  		}
+//  		k++
+//  	}
+//  	... assignments for elements with where[k] == 1
+//  }
+//
+// Masks of ELSEWHERE are used only for elements not used before.
+func (p *parser) parseWhere() (stmts []goast.Stmt) {
+	if !p.isStatement("WHERE") {
+		panic(fmt.Errorf("Not valid WHERE statement"))
+	}
+	p.ident++
+	args, end := separateArgsParen(p.ns[p.ident:])
+	if len(args) != 1 {
+		panic(fmt.Errorf("Not valid mask of WHERE"))
+	}
+	p.ident += end
+
+	// shape of mask
+	loops := p.exprLoops(args[0])
+	if loops == nil {
+		panic(fmt.Errorf("Mask of WHERE is not array expression"))
+	}
+
+	// amount of elements
+	var size []node
+	for i, l := range loops {
+		if i > 0 {
+			size = append(size, node{tok: token.MUL, b: []byte("*")})
+		}
+		size = append(size, paren(extent(l))...)
+	}
+	block := &goast.BlockStmt{List: []goast.Stmt{
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent("where")},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{&goast.CallExpr{
+				Fun:  goast.NewIdent("make"),
+				Args: []goast.Expr{goast.NewIdent("[]int"), p.parseValue(size)},
+			}},
+		},
+	}}
+
+	last := p.where
+	defer func() {
+		p.where = last
+	}()
+
+	mask := args[0]
+	for branch := 1; ; branch++ {
+		// elements of mask
+		cond := goast.Expr(&goast.BinaryExpr{
+			X:  goast.NewIdent("where[k]"),
+			Op: token.EQL,
+			Y:  goast.NewIdent("0"),
+		})
+		if mask != nil {
+			m, _ := p.elementalNodes(mask, loops, nil)
+			cond = &goast.BinaryExpr{
+				X:  cond,
+				Op: token.LAND,
+				Y:  p.parseValue(m),
+			}
+		}
+		body := []goast.Stmt{&goast.IfStmt{
+			Cond: cond,
+			Body: &goast.BlockStmt{List: []goast.Stmt{&goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("where[k]")},
+				Tok: token.ASSIGN,
+				Rhs: []goast.Expr{goast.NewIdent(fmt.Sprintf("%d", branch))},
+			}}},
+		}}
+		block.List = append(block.List, p.maskFor(loops, body))
+
+		// assignments
+		p.where = branch
+		if p.ns[p.ident].tok != ftNewLine {
+			// Example:
+			//  WHERE ( A > 0 ) A = SQRT ( A )
+			block.List = append(block.List, p.parseStmt()...)
+			break
+		}
+		block.List = append(block.List, p.parseListStmt()...)
+		if p.ident >= len(p.ns) || !p.isElseWhere() {
+			// END WHERE
+			break
+		}
+
+		// ELSEWHERE
+		if p.ns[p.ident].tok == token.ELSE {
+			p.ident++
+		}
+		p.ident++
+		mask = nil
+		if p.ns[p.ident].tok == token.LPAREN {
+			args, end := separateArgsParen(p.ns[p.ident:])
+			mask = args[0]
+			p.ident += end
+		}
+		p.gotoEndLine()
+	}
+
+	stmts = append(stmts, block)
+	return
+}
+
+// extent return amount of elements in loop.
+// Examples:
+//  N
+//  N - 2 + 1
+//  ( N - 2 + 2 ) / 2
+func extent(l arrayLoop) (out []node) {
+	if nodesToString(l.lower) == "1" && nodesToString(l.stride) == "1" {
+		return l.upper
+	}
+	out = append(out, paren(l.upper)...)
+	out = append(out, node{tok: token.SUB, b: []byte("-")})
+	out = append(out, paren(l.lower)...)
+	out = append(out, node{tok: token.ADD, b: []byte("+")})
+	out = append(out, paren(l.stride)...)
+	if nodesToString(l.stride) != "1" {
+		out = append(paren(out), node{tok: token.QUO, b: []byte("/")})
+		out = append(out, paren(l.stride)...)
+	}
+	return
+}
+
+// maskFor return loops with counter k of elements
+func (p *parser) maskFor(loops []arrayLoop, body []goast.Stmt) goast.Stmt {
+	body = append(body, &goast.IncDecStmt{X: goast.NewIdent("k"), Tok: token.INC})
+	where := p.where
+	p.where = 0
+	defer func() {
+		p.where = where
+	}()
+	return &goast.BlockStmt{List: []goast.Stmt{
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent("k")},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{goast.NewIdent("0")},
+		},
+		p.arrayFor(loops, body),
+	}}
+}
+
+// Example:
+//  FORALL ( I = 1 : N , J = 1 : N , I /= J ) A ( I , J ) = B ( J , I )
+//
+// Go code:
+//  for J := 1; J <= (*N); J++ {
+//  	for I := 1; I <= (*N); I++ {
+//  		if I != J {
+//  			(*A)[I-(1)][J-(1)] = (*B)[J-(1)][I-(1)]
+//  		}
+//  	}
+//  }
+//
+// If right part use array from left part, then all values are calculated
+// before assignment.
+func (p *parser) parseForall() (stmts []goast.Stmt) {
+	if !p.isStatement("FORALL") {
+		panic(fmt.Errorf("Not valid FORALL statement"))
+	}
+	p.ident++
+	args, end := separateArgsParen(p.ns[p.ident:])
+	p.ident += end
+
+	var loops []arrayLoop
+	var mask []node
+	for _, a := range args {
+		pos := -1
+		for i := range a {
+			if a[i].tok == token.ASSIGN {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			mask = a
+			continue
+		}
+		parts := splitColon(a[pos+1:])
+		if pos != 1 || a[0].tok != token.IDENT || len(parts) < 2 {
+			panic(fmt.Errorf("Not valid index of FORALL: %s", nodesToString(a)))
+		}
+		l := arrayLoop{
+			index:  string(a[0].b),
+			lower:  parts[0],
+			upper:  parts[1],
+			stride: []node{nodeOne},
+		}
+		if len(parts) > 2 {
+			l.stride = parts[2]
+		}
+		loops = append(loops, l)
+	}
+
+	// indexes are Go-local variables of loops, so variables with same
+	// names are not changed
+	for _, l := range loops {
+		if p.isVariable(l.index) {
+			// variable may be used only as index of FORALL
+			stmts = append(stmts, &goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("_")},
+				Tok: token.ASSIGN,
+				Rhs: []goast.Expr{goast.NewIdent(l.index)},
+			})
+		}
+		if p.arrayIndexes[l.index] {
+			continue
+		}
+		p.arrayIndexes[l.index] = true
+		defer delete(p.arrayIndexes, l.index)
+	}
+
+	var body []goast.Stmt
+	if p.ns[p.ident].tok != ftNewLine {
+		// Example:
+		//  FORALL ( I = 1 : N ) A ( I ) = I
+		body = p.parseStmt()
+	} else {
+		body = p.parseListStmt()
+	}
+
+	// loops over all indexes in column-major order
+	forall := func(body []goast.Stmt) goast.Stmt {
+		if mask != nil {
+			body = []goast.Stmt{&goast.IfStmt{
+				Cond: p.parseValue(mask),
+				Body: &goast.BlockStmt{List: body},
+			}}
+		}
+		for _, l := range loops {
+			index := []node{{tok: token.IDENT, b: []byte(l.index)}}
+			body = []goast.Stmt{p.forLoop(index, token.DEFINE,
+				l.lower, l.upper, l.stride, body)}
+		}
+		return body[0]
+	}
+
+	for _, s := range body {
+		assign, ok := s.(*goast.AssignStmt)
+		if !ok {
+			stmts = append(stmts, s)
+			continue
+		}
+		name := rootIdent(assign.Lhs[0])
+		v, ok := p.initVars.get(name)
+		if !ok || !isUsed(assign.Rhs[0], name) {
+			stmts = append(stmts, forall([]goast.Stmt{assign}))
+			continue
+		}
+		stmts = append(stmts, &goast.BlockStmt{List: []goast.Stmt{
+			&goast.DeclStmt{Decl: &goast.GenDecl{
+				Tok: token.VAR,
+				Specs: []goast.Spec{&goast.ValueSpec{
+					Names: []*goast.Ident{goast.NewIdent("buffer")},
+					Type:  goast.NewIdent("[]" + v.typ.getBaseType()),
+				}},
+			}},
+			forall([]goast.Stmt{&goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("buffer")},
+				Tok: token.ASSIGN,
+				Rhs: []goast.Expr{&goast.CallExpr{
+					Fun:  goast.NewIdent("append"),
+					Args: []goast.Expr{goast.NewIdent("buffer"), assign.Rhs[0]},
+				}},
+			}}),
+			forall([]goast.Stmt{
+				&goast.AssignStmt{
+					Lhs: assign.Lhs,
+					Tok: token.ASSIGN,
+					Rhs: []goast.Expr{goast.NewIdent("buffer[0]")},
+				},
+				&goast.AssignStmt{
+					Lhs: []goast.Expr{goast.NewIdent("buffer")},
+					Tok: token.ASSIGN,
+					Rhs: []goast.Expr{goast.NewIdent("buffer[1:]")},
+				},
+			}),
+		}})
+	}
+	return
+}
+
+// rootIdent return name of variable in expression of element.
+// Example:
+//  (*A)[(*I)-(1)]
+// Result:
+//  A
+func rootIdent(e goast.Expr) string {
+	for {
+		switch v := e.(type) {
+		case *goast.IndexExpr:
+			e = v.X
+		case *goast.ParenExpr:
+			e = v.X
+		case *goast.StarExpr:
+			e = v.X
+		case *goast.Ident:
+			return v.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// isUsed return true if expression use variable
+func isUsed(e goast.Expr, name string) (found bool) {
+	goast.Inspect(e, func(n goast.Node) bool {
+		if id, ok := n.(*goast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return
+}
@@ -1,6 +1,7 @@
 package fortran
 
 import (
+	"bytes"
 	"fmt"
 	goast "go/ast"
 	goparser "go/parser"
@@ -50,12 +51,17 @@ func (p *parser) parseExprNodes(in []node) (expr goast.Expr) {
 	nodes := make([]node, len(in))
 	copy(nodes, in)
 
+	if len(p.generics) > 0 {
+		p.fixGeneric(&nodes)
+	}
+	p.fixKeywordArgs(&nodes)
 	p.fixFakeParen(&nodes)
 	p.fixArrayVariables(&nodes)
 	if m, ok := p.fixVectorExplode(&nodes); ok {
 		nodes = m
 	}
 	p.fixDoubleStar(&nodes)
+	p.fixFloat(&nodes)
 	p.fixString(&nodes)
 	p.fixComplexValue(&nodes)
 	p.fixIdent(&nodes)
@@ -180,17 +186,17 @@ func (p *parser) fixArrayVariables(nodes *[]node) {
 					}
 				}
 			}
+			lower := []node{{tok: token.INT, b: []byte(strconv.Itoa(begin))}}
+			if v.typ.allocatable && v.typ.baseType != "string" {
+				// lower bound is saved by ALLOCATE
+				lower = p.lowerBound(v.name, i)
+			}
 			inject = append(inject, node{tok: token.LBRACK, b: []byte("[")})
 			inject = append(inject, a...)
-			inject = append(inject, []node{
-				{tok: token.SUB, b: []byte("-")},
-				{tok: token.LPAREN, b: []byte("(")},
-				{
-					tok: token.INT,
-					b:   []byte(strconv.Itoa(begin)),
-				},
-				{tok: token.RPAREN, b: []byte(")")},
-			}...)
+			inject = append(inject, node{tok: token.SUB, b: []byte("-")},
+				node{tok: token.LPAREN, b: []byte("(")})
+			inject = append(inject, lower...)
+			inject = append(inject, node{tok: token.RPAREN, b: []byte(")")})
 			inject = append(inject, node{tok: token.RBRACK, b: []byte("]")})
 		}
 
@@ -277,6 +283,20 @@ func (p *parser) fixString(nodes *[]node) {
 	}
 }
 
+// fixFloat change exponent of REAL literals with single precision to `E`,
+// because exponents `D` and `Q` of double precision are changed to `e`
+// by scanner.
+// Example:
+//  1.0E-5 for 1.0E-5
+//  1.0e-5 for 1.0D-5
+func (p *parser) fixFloat(nodes *[]node) {
+	for i := range *nodes {
+		if (*nodes)[i].tok == token.FLOAT && !(*nodes)[i].double {
+			(*nodes)[i].b = bytes.ToUpper((*nodes)[i].b)
+		}
+	}
+}
+
 // parse complex init
 // From :
 // ( 1.0E+0 , 0.0E+0 )
@@ -394,8 +414,10 @@ func (p *parser) fixConcatString(nodes *[]node) {
 		var found bool
 		for i, n := range *nodes {
 			if n.tok == ftStringConcat {
+				// concatenation from left to right
 				pos = i
 				found = true
+				break
 			}
 		}
 
@@ -406,24 +428,16 @@ func (p *parser) fixConcatString(nodes *[]node) {
 		leftOther, leftVariable, rightVariable, rightOther := p.split(nodes, pos)
 
 		// combine expression by next formula:
-		// leftOther append(append([]byte,leftVariable),rightVariable) rightOther
-		//           ---------------------            --             -
+		// leftOther intrinsic.CONCAT(leftVariable,rightVariable) rightOther
+		//           ----------------             -             -
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
 		var comb []node
 		comb = append(comb, leftOther...)
 		comb = append(comb, []node{
-			{tok: token.IDENT, b: []byte("append")},
+			{tok: token.IDENT, b: []byte("intrinsic.CONCAT")},
 			{tok: token.LPAREN, b: []byte("(")},
-			{tok: token.IDENT, b: []byte("append")},
-			{tok: token.LPAREN, b: []byte("(")},
-			{tok: token.LBRACK, b: []byte("[")},
-			{tok: token.RBRACK, b: []byte("]")},
-			{tok: token.IDENT, b: []byte("byte")},
-			{tok: token.LBRACE, b: []byte("{")},
-			{tok: token.RBRACE, b: []byte("}")},
-			{tok: token.COMMA, b: []byte(",")},
 		}...)
 		comb = append(comb, leftVariable...)
-		comb = append(comb, node{tok: token.RPAREN, b: []byte(")")})
 		comb = append(comb, node{tok: token.COMMA, b: []byte(",")})
 		comb = append(comb, rightVariable...)
 		comb = append(comb, node{tok: token.RPAREN, b: []byte(")")})
@@ -442,7 +456,19 @@ func (p *parser) fixIdent(nodes *[]node) {
 			// for function
 			continue
 		}
-		if s := string((*nodes)[i].b); s == "false" || s == "true" {
+		if s := string((*nodes)[i].b); s == "false" || s == "true" || s == "nil" {
+			continue
+		}
+		if p.arrayIndexes[string((*nodes)[i].b)] {
+			// Go-local index of array expression
+			continue
+		}
+		if v, ok := p.initVars.get(string((*nodes)[i].b)); ok && v.typ.constant {
+			// Go constant of module
+			continue
+		}
+		if v, ok := p.initVars.get(string((*nodes)[i].b)); ok && v.typ.value {
+			// argument passed by value
 			continue
 		}
 
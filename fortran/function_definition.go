@@ -80,6 +80,7 @@ func (in intrinsic) Visit(node goast.Node) (w goast.Visitor) {
 					"append",
 					"panic",
 					"new",
+					"int",
 					"real":
 				default:
 					n.Name = strings.ToUpper(n.Name)
@@ -124,6 +125,11 @@ func (in intrinsic) Visit(node goast.Node) (w goast.Visitor) {
 								call.Args[i] = id
 								continue
 							}
+							if id, ok := par.X.(*goast.Ident); ok && isRead && i == 1 {
+								// format of READ
+								call.Args[i] = id
+								continue
+							}
 						}
 					}
 				}
@@ -217,6 +223,44 @@ var intrinsicFunction = map[string]func(*parser, *goast.CallExpr){
 		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
 		intrinsicArgumentCorrection(p, f, "intrinsic.SQRT", typeNames)
 	},
+	"LGE": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any, any}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.LGE", typeNames)
+	},
+	"LGT": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any, any}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.LGT", typeNames)
+	},
+	"LLE": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any, any}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.LLE", typeNames)
+	},
+	"LLT": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any, any}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.LLT", typeNames)
+	},
+	"ALLOCATED": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.ALLOCATED", typeNames)
+	},
+	"PRESENT": func(p *parser, f *goast.CallExpr) {
+		// argument is pointer, that is nil for absent optional argument
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		f.Fun.(*goast.Ident).Name = "intrinsic.PRESENT"
+	},
+	"SIZE": func(p *parser, f *goast.CallExpr) {
+		typeNames := []string{any, "int"}
+		if len(f.Args) == 1 {
+			typeNames = typeNames[:1]
+		}
+		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+		intrinsicArgumentCorrection(p, f, "intrinsic.SIZE", typeNames)
+	},
 	"CMPLX": func(p *parser, f *goast.CallExpr) {
 		typeNames := []string{"any"}
 		p.addImport("github.com/Konstantin8105/f4go/intrinsic")
@@ -0,0 +1,785 @@
+package fortran
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"strings"
+)
+
+// signature is interface of procedure
+type signature struct {
+	name      string
+	params    []string
+	types     []goType // types of parameters, empty type for undefined
+	result    *goType  // type of FUNCTION result or nil
+	elemental bool
+}
+
+// specific is specific procedure of generic interface
+type specific struct {
+	name string // Go name of procedure
+	sig  signature
+}
+
+// collectSignatures find interfaces of all procedures in source
+// for resolve calls of generic interfaces by types of arguments.
+func (p *parser) collectSignatures() {
+	if p.signatures == nil {
+		p.signatures = map[string]signature{}
+	}
+	for i := 0; i+1 < len(p.ns); i++ {
+		switch p.ns[i].tok {
+		case ftSubroutine, ftFunction:
+		default:
+			continue
+		}
+		if p.ns[i+1].tok != token.IDENT {
+			continue
+		}
+		start := i
+		for start > 0 && p.ns[start-1].tok != ftNewLine {
+			start--
+		}
+		if sig, ok := p.procedureSignature(start, i); ok {
+			p.signatures[sig.name] = sig
+		}
+	}
+}
+
+// procedureSignature parse header and declarations of procedure
+// without changing state of parser.
+// Example:
+//  REAL * 8 FUNCTION DNORM ( X , N )
+//  INTEGER N
+//  REAL * 8 X ( N )
+func (p *parser) procedureSignature(start, pos int) (sig signature, ok bool) {
+	var (
+		ident     = p.ident
+		initVars  = p.initVars
+		constants = p.constants
+		errs      = len(p.errs)
+		pkgs      = map[string]bool{}
+	)
+	for k, v := range p.pkgs {
+		pkgs[k] = v
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+		p.ident = ident
+		p.initVars = initVars
+		p.constants = constants
+		p.errs = p.errs[:errs]
+		p.pkgs = pkgs
+	}()
+	p.initVars = varInits{}
+	p.constants = map[string][]node{}
+
+	prefix := p.ns[start:pos]
+	sig.name = strings.ToUpper(string(p.ns[pos+1].b))
+	sig.elemental = p.elementals[sig.name]
+	i := pos + 2
+	if i < len(p.ns) && p.ns[i].tok == token.LPAREN {
+		for ; i < len(p.ns) && p.ns[i].tok != token.RPAREN; i++ {
+			if p.ns[i].tok == token.IDENT {
+				sig.params = append(sig.params, string(p.ns[i].b))
+			}
+		}
+	}
+
+	// declarations
+declarations:
+	for i < len(p.ns) {
+		for i < len(p.ns) && p.ns[i].tok != ftNewLine {
+			i++
+		}
+		for i < len(p.ns) && p.ns[i].tok == ftNewLine {
+			i++
+		}
+		if i >= len(p.ns) {
+			break
+		}
+		switch p.ns[i].tok {
+		case ftEnd, ftContains:
+			break declarations
+		case ftInteger, ftCharacter, ftComplex, ftLogical, ftReal, ftDouble:
+			p.ident = i
+			p.parseInit()
+		}
+	}
+
+	for _, name := range sig.params {
+		var typ goType
+		if v, ok := p.initVars.get(name); ok {
+			typ = v.typ
+		}
+		sig.types = append(sig.types, typ)
+	}
+	if p.ns[pos].tok == ftFunction {
+		result := sig.name
+		if r, ok := p.results[sig.name]; ok {
+			result = r
+		}
+		if len(prefix) > 0 {
+			typ := parseType(prefix)
+			sig.result = &typ
+		} else if v, ok := p.initVars.get(result); ok {
+			sig.result = &v.typ
+		}
+	}
+	return sig, true
+}
+
+// genericName return name of generic interface.
+// Examples:
+//  NORM                  -> NORM
+//  OPERATOR ( + )        -> OPERATOR(+)
+//  OPERATOR ( .CROSS. )  -> OPERATOR(.CROSS.)
+func genericName(nodes []node) (name string, ok bool) {
+	switch {
+	case len(nodes) == 1 && nodes[0].tok == token.IDENT:
+		return strings.ToUpper(string(nodes[0].b)), true
+	case len(nodes) == 4 && nodes[0].tok == token.IDENT &&
+		strings.ToUpper(string(nodes[0].b)) == "OPERATOR" &&
+		nodes[1].tok == token.LPAREN && nodes[3].tok == token.RPAREN:
+		return operatorName(nodes[2]), true
+	}
+	return "", false
+}
+
+// operatorName return name of generic interface for operator
+func operatorName(n node) string {
+	if n.tok == ftDefinedOp {
+		return "OPERATOR(" + strings.ToUpper(string(n.b)) + ")"
+	}
+	return "OPERATOR(" + view(n.tok) + ")"
+}
+
+// parseInterface parse INTERFACE block.
+// Explicit interfaces of external procedures are ignored, because
+// signatures of all procedures are found before parsing.
+// Generic interfaces are saved for resolve calls by types of arguments.
+// Examples:
+//  INTERFACE
+//  SUBROUTINE SOLVE ( A , N )
+//  ...
+//  END SUBROUTINE
+//  END INTERFACE
+//
+//  INTERFACE NORM
+//  MODULE PROCEDURE SNORM , DNORM
+//  END INTERFACE
+//
+//  INTERFACE OPERATOR ( .CROSS. )
+//  MODULE PROCEDURE CROSS
+//  END INTERFACE
+func (p *parser) parseInterface() {
+	p.expect(ftInterface)
+	p.ident++
+	start := p.ident
+	p.gotoEndLine()
+	header := p.ns[start:p.ident]
+
+	var name string
+	if len(header) > 0 {
+		var ok bool
+		if name, ok = genericName(header); !ok {
+			p.addError("Not support interface : " + nodesToString(header))
+		}
+	}
+
+	var names []string
+	var body bool
+	for ; p.ident < len(p.ns); p.ident++ {
+		switch p.ns[p.ident].tok {
+		case ftEnd:
+			p.gotoEndLine()
+			if !body {
+				// END INTERFACE
+				goto end
+			}
+			body = false
+		case ftModule:
+			// Example:
+			//  MODULE PROCEDURE SNORM , DNORM
+			start := p.ident + 2
+			p.gotoEndLine()
+			for _, n := range p.ns[start:p.ident] {
+				if n.tok == token.IDENT {
+					names = append(names, strings.ToUpper(string(n.b)))
+				}
+			}
+		case ftSubroutine, ftFunction:
+			if !body && p.ident+1 < len(p.ns) && p.ns[p.ident+1].tok == token.IDENT {
+				names = append(names, strings.ToUpper(string(p.ns[p.ident+1].b)))
+				body = true
+			}
+			p.gotoEndLine()
+		}
+	}
+end:
+	if name == "" {
+		return
+	}
+	for _, n := range names {
+		p.generics[name] = append(p.generics[name], specific{name: n, sig: p.signatures[n]})
+	}
+}
+
+// fixGeneric change generic names and overloaded operators to
+// calls of specific procedures.
+// Example:
+//  NORM ( X , 3 )  ->  DNORM ( X , 3 )
+//  A .CROSS. B     ->  ( * CROSS ( A , B ) )
+func (p *parser) fixGeneric(nodes *[]node) {
+	*nodes = p.fixOperators(*nodes)
+	for i := len(*nodes) - 2; i >= 0; i-- {
+		n := (*nodes)[i]
+		if n.tok != token.IDENT || (*nodes)[i+1].tok != token.LPAREN {
+			continue
+		}
+		specifics, ok := p.generics[strings.ToUpper(string(n.b))]
+		if !ok {
+			continue
+		}
+		args, _ := separateArgsParen((*nodes)[i+1:])
+		if len(args) == 1 && len(args[0]) == 0 {
+			args = nil
+		}
+		s, ok := p.resolve(specifics, args, false)
+		if !ok {
+			p.addError("Cannot resolve generic procedure : " + nodesToString(*nodes))
+			continue
+		}
+		(*nodes)[i].b = []byte(p.specificName(s))
+	}
+}
+
+// specificName return Fortran name of specific procedure.
+// Unexported Go name of private module procedure is added in renames,
+// because names of called functions are in upper case.
+func (p *parser) specificName(s specific) string {
+	name := strings.ToUpper(s.name)
+	if name != s.name {
+		p.renames[name] = s.name
+	}
+	return name
+}
+
+// precedence return precedence of binary operator or 0.
+// Defined binary operator has lowest precedence.
+func precedence(tok token.Token) int {
+	switch tok {
+	case ftDefinedOp:
+		return 1
+	case token.LOR:
+		return 2
+	case token.LAND:
+		return 3
+	case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ:
+		return 5
+	case ftStringConcat:
+		return 6
+	case token.ADD, token.SUB:
+		return 7
+	case token.MUL, token.QUO:
+		return 8
+	case ftDoubleStar:
+		return 9
+	}
+	return 0
+}
+
+// isOperand return true for last node of operand
+func isOperand(n node) bool {
+	switch n.tok {
+	case token.IDENT, token.INT, token.FLOAT, token.STRING, token.CHAR, token.RPAREN:
+		return true
+	}
+	return false
+}
+
+// fixOperators change overloaded operators to calls of specific functions
+func (p *parser) fixOperators(nodes []node) []node {
+	if len(nodes) == 0 {
+		return nodes
+	}
+
+	// binary operator with lowest precedence on top level
+	pos, level, depth := -1, 0, 0
+	for i, n := range nodes {
+		switch n.tok {
+		case token.LPAREN:
+			depth++
+			continue
+		case token.RPAREN:
+			depth--
+			continue
+		}
+		if depth != 0 || i == 0 || !isOperand(nodes[i-1]) {
+			continue
+		}
+		if pr := precedence(n.tok); pr > 0 && (pos < 0 || pr <= level) {
+			pos, level = i, pr
+		}
+	}
+
+	if pos > 0 {
+		left := p.fixOperators(nodes[:pos])
+		right := p.fixOperators(nodes[pos+1:])
+		op := operatorName(nodes[pos])
+		specifics, ok := p.generics[op]
+		if ok {
+			if s, ok := p.resolve(specifics, [][]node{left, right}, true); ok {
+				return p.operatorCall(p.specificName(s), left, right)
+			}
+		}
+		if nodes[pos].tok == ftDefinedOp {
+			panic(fmt.Errorf("Cannot resolve operator %s", op))
+		}
+		return append(append(append([]node{}, left...), nodes[pos]), right...)
+	}
+
+	// unary defined operator
+	// Example:
+	//  .NEG. A
+	if nodes[0].tok == ftDefinedOp {
+		operand := p.fixOperators(nodes[1:])
+		op := operatorName(nodes[0])
+		if s, ok := p.resolve(p.generics[op], [][]node{operand}, true); ok {
+			return p.operatorCall(p.specificName(s), operand)
+		}
+		panic(fmt.Errorf("Cannot resolve operator %s", op))
+	}
+
+	// operators inside parens
+	var out []node
+	for i := 0; i < len(nodes); i++ {
+		if nodes[i].tok != token.LPAREN {
+			out = append(out, nodes[i])
+			continue
+		}
+		args, end := separateArgsParen(nodes[i:])
+		out = append(out, nodeLParen)
+		for j, a := range args {
+			if j > 0 {
+				out = append(out, node{tok: token.COMMA, b: []byte(",")})
+			}
+			out = append(out, p.fixOperators(a)...)
+		}
+		out = append(out, nodeRParen)
+		i += end - 1
+	}
+	return out
+}
+
+// operatorCall return nodes of call specific function of operator
+// Example:
+//  ( * CROSS ( A , B ) )
+func (p *parser) operatorCall(name string, args ...[]node) (nodes []node) {
+	nodes = append(nodes, nodeLParen,
+		node{tok: token.MUL, b: []byte("*")},
+		node{tok: token.IDENT, b: []byte(name)},
+		nodeLParen)
+	for i, a := range args {
+		if i > 0 {
+			nodes = append(nodes, node{tok: token.COMMA, b: []byte(",")})
+		}
+		nodes = append(nodes, a...)
+	}
+	return append(nodes, nodeRParen, nodeRParen)
+}
+
+// resolve return specific procedure for types of arguments.
+// Argument with unknown type is acceptable for any parameter,
+// if it is not strict resolving.
+func (p *parser) resolve(specifics []specific, args [][]node, strict bool) (s specific, ok bool) {
+	types := make([]*goType, len(args))
+	for i := range args {
+		if typ, ok := p.exprType(args[i]); ok {
+			types[i] = &typ
+		}
+	}
+	for _, s = range specifics {
+		if len(s.sig.params) != len(args) {
+			continue
+		}
+		match := true
+		for i, param := range s.sig.types {
+			switch {
+			case param.baseType == "":
+				// undefined type of parameter
+			case types[i] == nil:
+				match = match && !strict
+			default:
+				match = match && sameType(param, *types[i])
+			}
+		}
+		if match {
+			return s, true
+		}
+	}
+	return specific{}, false
+}
+
+// sameType return true for equal base types, precisions and ranks
+func sameType(a, b goType) bool {
+	if a.baseType != b.baseType {
+		return false
+	}
+	if a.baseType == "byte" {
+		// CHARACTER
+		return true
+	}
+	if a.single != b.single {
+		// REAL or COMPLEX with other kind
+		return false
+	}
+	return len(a.arrayNode) == len(b.arrayNode)
+}
+
+// exprType return type of expression for resolving generic procedures.
+// Result is not ok, if type is unknown.
+func (p *parser) exprType(nodes []node) (typ goType, ok bool) {
+	var (
+		operands [][]node
+		operand  []node
+		depth    int
+		logical  bool
+	)
+	for i, n := range nodes {
+		switch n.tok {
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			depth--
+		}
+		if depth == 0 && (precedence(n.tok) > 0 || n.tok == token.NOT) &&
+			(n.tok == token.NOT || (i > 0 && isOperand(nodes[i-1]))) {
+			switch precedence(n.tok) {
+			case 1:
+				return
+			case 0, 2, 3, 5:
+				logical = true
+			}
+			operands = append(operands, operand)
+			operand = nil
+			continue
+		}
+		if depth == 0 && i == 0 && (n.tok == token.SUB || n.tok == token.ADD) {
+			// unary operator
+			continue
+		}
+		operand = append(operand, n)
+	}
+	operands = append(operands, operand)
+	if logical {
+		return goType{baseType: "bool"}, true
+	}
+
+	// double precision, if any REAL or COMPLEX operand is double
+	var single, double bool
+	for i, o := range operands {
+		t, ok := p.operandType(o)
+		if !ok {
+			return goType{}, false
+		}
+		if t.baseType == "float64" || t.baseType == "complex128" {
+			single = single || t.single
+			double = double || !t.single
+		}
+		switch {
+		case i == 0:
+			typ = t
+		case t.baseType == "complex128" || typ.baseType == "complex128":
+			typ.baseType = "complex128"
+		case t.baseType == "float64" || typ.baseType == "float64":
+			typ.baseType = "float64"
+		}
+		if len(t.arrayNode) > len(typ.arrayNode) {
+			typ.arrayNode = t.arrayNode
+		}
+	}
+	typ.single = single && !double
+	return typ, true
+}
+
+// operandType return type of operand of expression
+func (p *parser) operandType(nodes []node) (typ goType, ok bool) {
+	if len(nodes) == 0 {
+		return
+	}
+	if nodes[0].tok == token.MUL && len(nodes) > 1 {
+		// value of function result
+		// Example:
+		//  * CROSS ( A , B )
+		nodes = nodes[1:]
+	}
+	if len(nodes) == 1 {
+		n := nodes[0]
+		switch n.tok {
+		case token.INT:
+			return goType{baseType: "int"}, true
+		case token.FLOAT:
+			return goType{baseType: "float64", single: !n.double}, true
+		case token.STRING, token.CHAR:
+			return goType{baseType: "byte"}, true
+		case token.IDENT:
+			if s := string(n.b); s == "true" || s == "false" {
+				return goType{baseType: "bool"}, true
+			}
+			if v, ok := p.initVars.get(string(n.b)); ok {
+				return v.typ, true
+			}
+		}
+		return
+	}
+
+	if nodes[0].tok == token.LPAREN {
+		args, end := separateArgsParen(nodes)
+		if end != len(nodes) {
+			return
+		}
+		if len(args) == 2 {
+			// complex value
+			typ = goType{baseType: "complex128", single: true}
+			for _, a := range args {
+				if t, ok := p.exprType(a); !ok || (t.baseType == "float64" && !t.single) {
+					typ.single = false
+				}
+			}
+			return typ, true
+		}
+		return p.exprType(args[0])
+	}
+
+	if nodes[1].tok != token.LPAREN {
+		return
+	}
+	args, end := separateArgsParen(nodes[1:])
+	if end != len(nodes)-1 {
+		return
+	}
+	if nodes[0].tok == ftReal {
+		return goType{baseType: "float64", single: true}, true
+	}
+	if nodes[0].tok != token.IDENT {
+		return
+	}
+	name := strings.ToUpper(string(nodes[0].b))
+	if v, ok := p.initVars.get(string(nodes[0].b)); ok {
+		// element or section of array
+		typ = v.typ
+		typ.arrayNode = nil
+		for _, a := range args {
+			for _, n := range a {
+				if n.tok == token.COLON {
+					typ.arrayNode = append(typ.arrayNode, a)
+					break
+				}
+			}
+		}
+		return typ, true
+	}
+	if specifics, found := p.generics[name]; found {
+		// call of generic function
+		if s, found := p.resolve(specifics, args, true); found && s.sig.result != nil {
+			return *s.sig.result, true
+		}
+		return
+	}
+	if sig, ok := p.signatures[name]; ok && sig.result != nil {
+		return *sig.result, true
+	}
+	switch name {
+	case "DBLE", "DFLOAT", "DSQRT":
+		return goType{baseType: "float64"}, true
+	case "FLOAT", "SNGL":
+		return goType{baseType: "float64", single: true}, true
+	case "SQRT":
+		if len(args) == 1 {
+			return p.exprType(args[0])
+		}
+	case "INT", "NINT", "IFIX", "IDINT":
+		return goType{baseType: "int"}, true
+	case "CMPLX":
+		return goType{baseType: "complex128", single: true}, true
+	case "DCMPLX":
+		return goType{baseType: "complex128"}, true
+	}
+	return
+}
+
+// signatureOf return signature of procedure by local name
+func (p *parser) signatureOf(name string) (sig signature, ok bool) {
+	name = strings.ToUpper(name)
+	if sig, ok = p.signatures[name]; ok {
+		return
+	}
+	if to, found := p.renames[name]; found {
+		// renamed by USE
+		sig, ok = p.signatures[strings.ToUpper(to)]
+	}
+	return
+}
+
+// isKeywordArg return true for keyword argument.
+// Example:
+//  TOL = 1.0D-6
+func isKeywordArg(arg []node) bool {
+	return len(arg) > 2 && arg[0].tok == token.IDENT && arg[1].tok == token.ASSIGN
+}
+
+// fixKeywordArgs change keyword arguments to positional arguments
+// and add nil for absent optional arguments.
+// Example:
+//  SOLVE ( A , TOL = 1.0D-6 )  ->  SOLVE ( A , nil , 1.0D-6 )
+func (p *parser) fixKeywordArgs(nodes *[]node) {
+	for i := len(*nodes) - 2; i >= 0; i-- {
+		n := (*nodes)[i]
+		if n.tok != token.IDENT || (*nodes)[i+1].tok != token.LPAREN ||
+			p.isVariable(string(n.b)) {
+			continue
+		}
+		sig, ok := p.signatureOf(string(n.b))
+		if !ok {
+			continue
+		}
+		args, end := separateArgsParen((*nodes)[i+1:])
+		if len(args) == 1 && len(args[0]) == 0 {
+			args = nil
+		}
+		var keyword bool
+		for _, a := range args {
+			keyword = keyword || isKeywordArg(a)
+		}
+		if !keyword && len(args) >= len(sig.params) {
+			continue
+		}
+
+		ordered := make([][]node, len(sig.params))
+		for j, a := range args {
+			if !isKeywordArg(a) {
+				if j < len(ordered) {
+					ordered[j] = a
+				}
+				continue
+			}
+			name := strings.ToUpper(string(a[0].b))
+			k := 0
+			for ; k < len(sig.params) && sig.params[k] != name; k++ {
+			}
+			if k == len(sig.params) {
+				p.addError(fmt.Sprintf("Not valid keyword argument %s of %s", name, sig.name))
+				return
+			}
+			ordered[k] = a[2:]
+		}
+
+		inject := []node{nodeLParen}
+		for j, a := range ordered {
+			if j > 0 {
+				inject = append(inject, node{tok: token.COMMA, b: []byte(",")})
+			}
+			if a == nil {
+				if !sig.types[j].optional {
+					p.addError(fmt.Sprintf("Absent argument %s of %s", sig.params[j], sig.name))
+				}
+				a = []node{{tok: token.IDENT, b: []byte("nil")}}
+			}
+			inject = append(inject, a...)
+		}
+		inject = append(inject, nodeRParen)
+		*nodes = append((*nodes)[:i+1], append(inject, (*nodes)[i+1+end:]...)...)
+	}
+}
+
+// go/ast Visitor for pass arguments by value, if parameter is
+// INTENT(IN) scalar. Expressions for other scalar parameters are
+// passed by pointer to temporary variable.
+// Example:
+//  From : SCALE(&((*N)), func()*int{y:=3;return &y}())
+//  To   : SCALE((*N), *func()*int{y:=3;return &y}())
+//
+//  From : FACT((*N) - 1)
+//  To   : FACT(func() *int { y := (*N) - 1; return &y }())
+type valueArgs struct {
+	p *parser
+}
+
+func (v valueArgs) Visit(node goast.Node) goast.Visitor {
+	call, ok := node.(*goast.CallExpr)
+	if !ok {
+		return v
+	}
+	id, ok := call.Fun.(*goast.Ident)
+	if !ok {
+		return v
+	}
+	sig, ok := v.p.signatureOf(id.Name)
+	if !ok || len(sig.types) != len(call.Args) {
+		return v
+	}
+	for i := range call.Args {
+		if !sig.types[i].value {
+			call.Args[i] = temporary(call.Args[i], sig.types[i])
+			continue
+		}
+		switch a := call.Args[i].(type) {
+		case *goast.UnaryExpr:
+			if a.Op != token.AND {
+				break
+			}
+			call.Args[i] = a.X
+			if par, ok := a.X.(*goast.ParenExpr); ok {
+				call.Args[i] = par.X
+			}
+		case *goast.Ident:
+			if strings.HasPrefix(a.Name, "func()*") {
+				a.Name = "*" + a.Name
+			}
+		case *goast.CallExpr:
+			if !isIgnoreCall(a) {
+				call.Args[i] = &goast.StarExpr{X: a}
+			}
+		}
+	}
+	return v
+}
+
+// temporary return pointer to temporary variable with value of
+// expression for scalar parameter with type typ.
+func temporary(arg goast.Expr, typ goType) goast.Expr {
+	if typ.baseType == "" || typ.baseType == "byte" || typ.isArray() {
+		return arg
+	}
+	switch a := arg.(type) {
+	case *goast.BinaryExpr:
+	case *goast.UnaryExpr:
+		if a.Op == token.AND {
+			return arg
+		}
+	default:
+		return arg
+	}
+	return &goast.CallExpr{Fun: &goast.FuncLit{
+		Type: &goast.FuncType{
+			Params: &goast.FieldList{},
+			Results: &goast.FieldList{List: []*goast.Field{
+				{Type: goast.NewIdent("*" + typ.String())},
+			}},
+		},
+		Body: &goast.BlockStmt{List: []goast.Stmt{
+			&goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("y")},
+				Tok: token.DEFINE,
+				Rhs: []goast.Expr{arg},
+			},
+			&goast.ReturnStmt{Results: []goast.Expr{
+				&goast.UnaryExpr{Op: token.AND, X: goast.NewIdent("y")},
+			}},
+		}},
+	}}
+}
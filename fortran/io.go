@@ -4,37 +4,261 @@ import (
 	"bytes"
 	"fmt"
 	goast "go/ast"
-	goparser "go/parser"
 	"go/token"
 	"strconv"
-	"unicode"
+	"strings"
 )
 
+// Runtime is Go expression of input/output runtime in generated code
+// with type *intrinsic.Runtime. Statements of input/output are method
+// calls of the expression. By default statements are calls of package
+// functions with default runtime.
+//
+// Expression is fixed at translation time, so it is one runtime per
+// translated package: all goroutines calling routines of the package
+// share unit table of the runtime. Workloads with own units must be
+// translated to different packages with different runtimes.
 // Example:
+//  Runtime = "RT"
+// Go code:
+//  RT.WRITE(6, []byte("*"), (*N))
+// Runtime is declared in other file of the package:
+//  var RT = intrinsic.NewRuntime()
+var Runtime = "intrinsic"
+
+// ioStatements is names of runtime functions of input/output statements
+// and statements with messages to unit 0 and input from unit 5
+var ioStatements = map[string]bool{
+	"OPEN": true, "CLOSE": true, "INQUIRE": true,
+	"REWIND": true, "BACKSPACE": true, "ENDFILE": true,
+	"WRITE": true, "READ": true, "WRITENML": true, "READNML": true,
+	"STOP": true, "ERROR_STOP": true, "PAUSE": true,
+}
+
+// ioRuntime change calls of input/output statements to method calls
+// of Runtime. Import of package intrinsic is removed, if package is
+// not used.
+func (p *parser) ioRuntime(decls []goast.Decl) {
+	if Runtime == "intrinsic" {
+		return
+	}
+	used := strings.HasPrefix(Runtime, "intrinsic.")
+	for _, d := range decls {
+		goast.Inspect(d, func(n goast.Node) bool {
+			switch n := n.(type) {
+			case *goast.SelectorExpr:
+				if x, ok := n.X.(*goast.Ident); ok && x.Name == "intrinsic" {
+					if ioStatements[n.Sel.Name] {
+						n.X = goast.NewIdent(Runtime)
+					} else {
+						used = true
+					}
+				}
+			case *goast.Ident:
+				if name := strings.TrimPrefix(n.Name, "intrinsic."); name != n.Name {
+					if ioStatements[name] {
+						n.Name = Runtime + "." + name
+					} else {
+						used = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	if !used {
+		delete(p.pkgs, "github.com/Konstantin8105/f4go/intrinsic")
+	}
+}
+
+// parseFilePositioning return statements REWIND, BACKSPACE and ENDFILE.
+// Examples:
 //  REWIND NTRA
 //  REWIND MSTP(161)
-func (p *parser) parseRewind() (stmts []goast.Stmt) {
-	p.expect(ftRewind)
+//  BACKSPACE ( 10 )
+//  ENDFILE ( UNIT = NOUT , IOSTAT = IOS , ERR = 100 )
+// Go code:
+//  intrinsic.REWIND("UNIT", (*NTRA))
+func (p *parser) parseFilePositioning() (stmts []goast.Stmt) {
+	name := strings.ToUpper(string(p.ns[p.ident].b))
 	p.ident++
 
-	var name string
-	for ; p.ident < len(p.ns) && p.ns[p.ident].tok != ftNewLine; p.ident++ {
-		name += string(p.ns[p.ident].b)
+	start := p.ident
+	p.gotoEndLine()
+	unit := p.ns[start:p.ident]
+	p.expect(ftNewLine)
+
+	args := [][]node{unit}
+	if len(unit) > 0 && unit[0].tok == token.LPAREN {
+		if list, end := separateArgsParen(unit); end == len(unit) {
+			args = list
+		}
 	}
+	if len(unit) == 0 {
+		panic(fmt.Errorf("Unit is not found in %s", name))
+	}
+
+	specifiers, labels := p.ioSpecifiers(name, args, []string{"UNIT"}, nil)
+	return append(stmts, p.ioCall(name, specifiers, labels))
+}
+
+// Example:
+//  INQUIRE ( FILE = NAME , EXIST = EX )
+//  INQUIRE ( UNIT = U , OPENED = OP , NAME = FN )
+//  INQUIRE ( 10 , OPENED = OP )
+// Go code:
+//  intrinsic.INQUIRE("FILE", (*NAME), "EXIST", EX)
+func (p *parser) parseInquire() (stmts []goast.Stmt) {
+	p.expect(ftInquire)
+	p.ident++
+	p.expect(token.LPAREN)
+	args, end := separateArgsParen(p.ns[p.ident:])
+	p.ident += end
 	p.expect(ftNewLine)
 
+	specifiers, labels := p.ioSpecifiers("INQUIRE", args,
+		[]string{"UNIT", "FILE"},
+		[]string{"EXIST", "OPENED", "NUMBER", "NAMED", "NAME",
+			"ACCESS", "SEQUENTIAL", "DIRECT",
+			"FORM", "FORMATTED", "UNFORMATTED",
+			"RECL", "NEXTREC", "POSITION", "SIZE",
+			"ACTION", "READ", "WRITE", "READWRITE"})
+	return append(stmts, p.ioCall("INQUIRE", specifiers, labels))
+}
+
+// ioSpecifiers return pairs of name and value of specifiers of
+// input/output statement. First argument without name is unit.
+// Value of specifier from list variables is address of variable.
+// Specifiers IOSTAT and IOMSG are always allowed. Labels of specifiers
+// ERR, END and EOR are returned in map.
+//
+// Example:
+//  ( 10 , FILE = NAME , IOSTAT = IOS , ERR = 100 )
+// Go code:
+//  "UNIT", 10, "FILE", (*NAME), "IOSTAT", IOS, "ERR", 100
+func (p *parser) ioSpecifiers(stmt string, args [][]node, values, variables []string) (
+	specifiers []goast.Expr, labels map[string]string) {
+
+	in := func(key string, list []string) bool {
+		for _, l := range list {
+			if key == l {
+				return true
+			}
+		}
+		return false
+	}
+
+	labels = map[string]string{}
+	for i, arg := range args {
+		key, value := "UNIT", arg
+		if len(arg) > 2 && arg[1].tok == token.ASSIGN {
+			key, value = strings.ToUpper(string(arg[0].b)), arg[2:]
+		} else if i != 0 {
+			p.addError(fmt.Sprintf("Not valid specifier of %s: %s",
+				stmt, nodesToString(arg)))
+			continue
+		}
+		var expr goast.Expr
+		switch {
+		case key == "ERR" || key == "END" || key == "EOR":
+			labels[key] = nodesToString(value)
+			expr = goast.NewIdent(labels[key])
+		case key == "IOSTAT" || key == "IOMSG" || in(key, variables):
+			expr = p.addressOf(value)
+		case in(key, values):
+			expr = p.parseExprNodes(value)
+		default:
+			p.addError(fmt.Sprintf("Specifier %s is not supported in %s", key, stmt))
+			continue
+		}
+		specifiers = append(specifiers, goast.NewIdent(strconv.Quote(key)), expr)
+	}
+	return
+}
+
+// ioCall return statement with call of function of runtime.
+// If specifier ERR is present, then status of statement is checked.
+//
+// Go code:
+//  if intrinsic.OPEN("UNIT", 10, "ERR", 100) > 0 {
+//  	goto Label100
+//  }
+func (p *parser) ioCall(stmt string, specifiers []goast.Expr, labels map[string]string) goast.Stmt {
 	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
 
-	s := fmt.Sprintf("intrinsic.REWIND(%s)", name)
+	call := &goast.CallExpr{
+		Fun: &goast.SelectorExpr{
+			X:   goast.NewIdent("intrinsic"),
+			Sel: goast.NewIdent(stmt),
+		},
+		Args: specifiers,
+	}
+	return p.ioBranch(call, labels)
+}
 
-	ast, err := goparser.ParseExpr(s)
-	if err != nil {
-		panic(fmt.Errorf("pos:%v\nSource : %v\n Error: %v", p.ns[p.ident].pos, s, err))
+// ioBranch return statement with call of input/output and branches
+// to labels of specifiers. Status of statement is positive for error,
+// -1 for end of file and -2 for end of record.
+//
+// Example:
+//  READ ( 5 , * , ERR = 200 , END = 100 ) X
+// Go code:
+//  if iostat := intrinsic.READ(...); iostat > 0 {
+//  	goto Label200
+//  } else if iostat == -1 {
+//  	goto Label100
+//  }
+func (p *parser) ioBranch(call goast.Expr, labels map[string]string) goast.Stmt {
+	branches := []struct {
+		key   string
+		op    token.Token
+		value string
+	}{
+		{key: "ERR", op: token.GTR, value: "0"},
+		{key: "END", op: token.EQL, value: "-1"},
+		{key: "EOR", op: token.EQL, value: "-2"},
 	}
 
-	return append(stmts, &goast.ExprStmt{
-		X: ast,
-	})
+	x := call
+	var init goast.Stmt
+	if len(labels) > 1 {
+		// status is checked several times
+		x = goast.NewIdent("iostat")
+		init = &goast.AssignStmt{
+			Lhs: []goast.Expr{x},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{call},
+		}
+	}
+
+	var stmt goast.Stmt = &goast.ExprStmt{X: call}
+	for i := len(branches) - 1; i >= 0; i-- {
+		b := branches[i]
+		label, ok := labels[b.key]
+		if !ok {
+			continue
+		}
+		p.foundLabels["Label"+label] = true
+		ifStmt := &goast.IfStmt{
+			Cond: &goast.BinaryExpr{
+				X:  x,
+				Op: b.op,
+				Y:  goast.NewIdent(b.value),
+			},
+			Body: &goast.BlockStmt{List: []goast.Stmt{&goast.BranchStmt{
+				Tok:   token.GOTO,
+				Label: goast.NewIdent("Label" + label),
+			}}},
+		}
+		if next, ok := stmt.(*goast.IfStmt); ok {
+			ifStmt.Else = next
+		}
+		stmt = ifStmt
+	}
+	if ifStmt, ok := stmt.(*goast.IfStmt); ok {
+		ifStmt.Init = init
+	}
+	return stmt
 }
 
 // Example:
@@ -55,42 +279,206 @@ func (p *parser) parseWrite() (stmts []goast.Stmt) {
 		p.ident += end
 
 		// Pattern:
-		//  WRITE( UNIT = ..., FMT = ...)
+		//  WRITE( UNIT = ..., FMT = ..., REC = ...)
 		// Other parameters are ignored
 
 		// Part : UNIT
-		unit := string(args[0][0].b)
-		if len(args[0]) == 3 {
-			unit = string(args[0][2].b)
+		// Unit is integer expression or CHARACTER variable of internal file.
+		// Examples:
+		//  UNIT = 6
+		//  STR
+		//  LINES ( I )
+		unitNodes := args[0]
+		if len(unitNodes) > 2 && unitNodes[1].tok == token.ASSIGN {
+			unitNodes = unitNodes[2:]
 		}
+		unit := nodesToString(unitNodes)
 		if unit == "*" {
 			unit = "6"
+			if strings.ToUpper(string(p.ns[start].b)) == "READ" {
+				// standard input
+				unit = "5"
+			}
+		}
+
+		// Part: specifiers of data transfer
+		// Examples:
+		//  REC = K
+		//  IOSTAT = IOS
+		//  END = 100
+		var control [][]node
+		for i := 1; i < len(args); i++ {
+			arg := args[i]
+			if len(arg) > 2 && arg[1].tok == token.ASSIGN {
+				switch strings.ToUpper(string(arg[0].b)) {
+				case "REC", "IOSTAT", "IOMSG", "ERR", "END", "EOR":
+					control = append(control, arg)
+					args = append(args[:i], args[i+1:]...)
+					i--
+				}
+			}
+		}
+
+		// Part: NML
+		if len(args) == 2 {
+			if group, ok := p.namelistGroup(args[1]); ok {
+				p.expect(ftNewLine)
+				return p.controlList(p.parseNamelistIO(unit, group), unit, control)
+			}
 		}
 
 		// Part: FMT
-		fmts := args[1][0]
-		if len(args[1]) == 3 {
-			fmts = args[1][2]
+		// Statement without format is unformatted.
+		// Examples:
+		//  WRITE ( 10 ) A , B
+		//  READ ( 10 , IOSTAT = IOS ) A , B
+		if len(args) < 2 || (len(args[1]) > 2 && args[1][1].tok == token.ASSIGN &&
+			strings.ToUpper(string(args[1][0].b)) != "FMT") {
+			isRead := strings.ToUpper(string(p.ns[start].b)) == "READ"
+			p.ns = append(p.ns[:start+2], append(scan([]byte(unit+" , 0 )")), p.ns[p.ident:]...)...)
+			p.ident = start
+			stmts = p.parseStmtWrite()
+			p.unformattedItems(stmts, isRead)
+			return p.controlList(stmts, unit, control)
+		}
+		fmtNodes := args[1]
+		if len(fmtNodes) > 2 && fmtNodes[1].tok == token.ASSIGN {
+			fmtNodes = fmtNodes[2:]
 		}
 
+		// Part: text of format specification
+		// Examples:
+		//  *
+		//  100
+		//  '(A80)'
+		// Other format is CHARACTER expression or CHARACTER array
+		// interpreted at runtime.
+		// Examples:
+		//  FMT
+		//  '(' // NCOL // 'F10.3)'
 		var fs string
-		if fmts.tok == token.INT {
-			line := p.getLineByLabel(fmts.b)
-			fs = p.parseFormat(line[2:])
-		} else if fmts.tok == token.MUL {
-			// Example: *
-			fs = "\" %v\\n\""
-		} else {
-			// Example :
-			// '(A80)'
-			ns := scan(fmts.b[2 : len(fmts.b)-2])
-			fs = p.parseFormat(ns)
+		var fmtExpr goast.Expr
+		switch {
+		case len(fmtNodes) == 1 && fmtNodes[0].tok == token.INT:
+			line := p.getLineByLabel(fmtNodes[0].b)
+			if len(line) < 2 {
+				panic(fmt.Errorf("Not valid FORMAT with label %s", fmtNodes[0].b))
+			}
+			fs = formatText(line[2:])
+		case len(fmtNodes) == 1 && fmtNodes[0].tok == token.MUL:
+			fs = "*"
+		case len(fmtNodes) == 1 && fmtNodes[0].tok == token.STRING:
+			fs = string(fmtNodes[0].b[1 : len(fmtNodes[0].b)-1])
+		default:
+			fmtExpr = p.parseExprNodes(fmtNodes)
 		}
 
-		p.ns = append(p.ns[:start+2], append(scan([]byte(fmt.Sprintf("%v , %v )", unit, fs))), p.ns[p.ident:]...)...)
+		p.ns = append(p.ns[:start+2], append(scan([]byte(unit+" , 0 )")), p.ns[p.ident:]...)...)
 
+		isRead := strings.ToUpper(string(p.ns[start].b)) == "READ"
 		p.ident = start
+		stmts = p.parseStmtWrite()
+		if call, ok := ioCallExpr(stmts); ok && len(call.Args) > 1 {
+			call.Args[1] = goast.NewIdent(fmt.Sprintf("[]byte(%s)", strconv.Quote(fs)))
+			if fmtExpr != nil {
+				call.Args[1] = fmtExpr
+			}
+			if !isRead {
+				characterItems(call)
+				if fs == "*" {
+					p.singleItems(call, false)
+				}
+			}
+		}
+		stmts = p.controlList(stmts, unit, control)
 	}
+	return
+}
+
+// parsePrint return statement PRINT as WRITE to standard output.
+// Examples:
+//  PRINT * , 'N = ' , N
+//  PRINT 100 , X
+//  PRINT '(I5)' , N
+// To:
+//  WRITE ( * , * ) 'N = ' , N
+func (p *parser) parsePrint() (stmts []goast.Stmt) {
+	start := p.ident
+	p.expect(ftPrint)
+
+	// format is until first comma
+	end := start + 1
+	for counter := 0; p.ns[end].tok != ftNewLine; end++ {
+		switch p.ns[end].tok {
+		case token.LPAREN:
+			counter++
+		case token.RPAREN:
+			counter--
+		}
+		if counter == 0 && p.ns[end].tok == token.COMMA {
+			break
+		}
+	}
+
+	write := append([]node{
+		{tok: ftWrite, b: []byte("WRITE")},
+		{tok: token.LPAREN, b: []byte("(")},
+		{tok: token.MUL, b: []byte("*")},
+		{tok: token.COMMA, b: []byte(",")},
+	}, p.ns[start+1:end]...)
+	write = append(write, node{tok: token.RPAREN, b: []byte(")")})
+	if p.ns[end].tok == token.COMMA {
+		end++
+	}
+	p.ns = append(p.ns[:start], append(write, p.ns[end:]...)...)
+	p.ident = start
+	return p.parseWrite()
+}
+
+// ioCallExpr return call of function of runtime in statements
+func ioCallExpr(stmts []goast.Stmt) (call *goast.CallExpr, ok bool) {
+	if len(stmts) != 1 {
+		return
+	}
+	e, ok := stmts[0].(*goast.ExprStmt)
+	if !ok {
+		return
+	}
+	call, ok = e.X.(*goast.CallExpr)
+	return
+}
+
+// controlList change unit of data transfer statement to unit with
+// specifiers and add branches to labels of specifiers.
+// Example:
+//  READ ( 10 , REC = K , IOSTAT = IOS ) A
+// Go code:
+//  intrinsic.READ(intrinsic.CONTROL("UNIT", 10, "REC", (*K), "IOSTAT", IOS), nil, A)
+func (p *parser) controlList(stmts []goast.Stmt, unit string, control [][]node) []goast.Stmt {
+	call, ok := ioCallExpr(stmts)
+	if !ok || len(control) == 0 || len(call.Args) == 0 {
+		return stmts
+	}
+	specifiers, labels := p.ioSpecifiers("data transfer",
+		append([][]node{scan([]byte(unit))}, control...),
+		[]string{"UNIT", "REC"}, nil)
+	call.Args[0] = &goast.CallExpr{
+		Fun: &goast.SelectorExpr{
+			X:   goast.NewIdent("intrinsic"),
+			Sel: goast.NewIdent("CONTROL"),
+		},
+		Args: specifiers,
+	}
+	return []goast.Stmt{p.ioBranch(call, labels)}
+}
+
+// parseStmtWrite return call of function WRITE of runtime.
+// Example:
+//  WRITE ( 6 , 0 ) A , B
+// Go code:
+//  intrinsic.WRITE(6, 0, (*A), (*B))
+func (p *parser) parseStmtWrite() (stmts []goast.Stmt) {
+	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
 	start := p.ident
 	p.ns = append(p.ns[:p.ident], append([]node{{tok: ftCall, b: []byte("call")}}, p.ns[p.ident:]...)...)
 
@@ -129,6 +517,154 @@ func (p *parser) parseWrite() (stmts []goast.Stmt) {
 	return p.parseStmt()
 }
 
+// unformattedItems change format of unformatted input/output to nil
+// and mark items with single precision.
+// Example:
+//  REAL X
+//  WRITE ( 10 ) X
+// Go code:
+//  intrinsic.WRITE(10, nil, intrinsic.SINGLE((*X)))
+func (p *parser) unformattedItems(stmts []goast.Stmt, isRead bool) {
+	call, ok := ioCallExpr(stmts)
+	if !ok || len(call.Args) < 2 {
+		return
+	}
+	call.Args[1] = goast.NewIdent("nil")
+	p.singleItems(call, isRead)
+}
+
+// singleItems mark items of input/output list with single precision,
+// because REAL and COMPLEX are stored in float64 and complex128.
+// Items of READ are addresses of variables.
+func (p *parser) singleItems(call *goast.CallExpr, isRead bool) {
+	for i := 2; i < len(call.Args); i++ {
+		if single, double := p.precision(call.Args[i]); !single || double {
+			continue
+		}
+		arg := call.Args[i]
+		if isRead {
+			arg = &goast.UnaryExpr{Op: token.AND, X: arg}
+			if par, ok := call.Args[i].(*goast.ParenExpr); ok {
+				if st, ok := par.X.(*goast.StarExpr); ok {
+					arg = st.X
+				}
+			}
+		}
+		call.Args[i] = &goast.CallExpr{
+			Fun: &goast.SelectorExpr{
+				X:   goast.NewIdent("intrinsic"),
+				Sel: goast.NewIdent("SINGLE"),
+			},
+			Args: []goast.Expr{arg},
+		}
+	}
+}
+
+// precision return precision of REAL and COMPLEX values in expression.
+// Expression is single precision, if single is true and double is false.
+// Literals of single precision have exponent `E`, see fixFloat.
+// Example:
+//  R * 2.0     single
+//  R * 2.0D0   double
+//  REAL ( D )  single
+//  SQRT ( R )  single
+func (p *parser) precision(expr goast.Expr) (single, double bool) {
+	switch v := expr.(type) {
+	case *goast.BasicLit:
+		if v.Kind == token.FLOAT {
+			double = strings.Contains(v.Value, "e")
+			single = !double
+		}
+	case *goast.ParenExpr:
+		return p.precision(v.X)
+	case *goast.StarExpr:
+		return p.precision(v.X)
+	case *goast.UnaryExpr:
+		return p.precision(v.X)
+	case *goast.IndexExpr:
+		return p.precision(v.X)
+	case *goast.SliceExpr:
+		return p.precision(v.X)
+	case *goast.Ident:
+		if v, ok := p.initVars.get(v.Name); ok &&
+			(v.typ.baseType == "float64" || v.typ.baseType == "complex128") {
+			return v.typ.single, !v.typ.single
+		}
+	case *goast.BinaryExpr:
+		switch v.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ,
+			token.LAND, token.LOR:
+			// result is LOGICAL
+			return
+		}
+		xs, xd := p.precision(v.X)
+		ys, yd := p.precision(v.Y)
+		return xs || ys, xd || yd
+	case *goast.CallExpr:
+		var name string
+		switch f := v.Fun.(type) {
+		case *goast.Ident:
+			name = strings.ToUpper(f.Name)
+		case *goast.ParenExpr:
+			// value of function result
+			return p.precision(f.X)
+		default:
+			return
+		}
+		if sig, ok := p.signatureOf(name); ok {
+			if r := sig.result; r != nil &&
+				(r.baseType == "float64" || r.baseType == "complex128") {
+				return r.single, !r.single
+			}
+			return
+		}
+		if _, ok := p.initVars.get(name); ok {
+			return
+		}
+		switch name {
+		case "REAL", "FLOAT", "SNGL", "CMPLX", "AIMAG", "ALOG", "ALOG10",
+			"AMAX1", "AMIN1", "AMOD":
+			return true, false
+		case "DBLE", "DFLOAT", "DCMPLX", "DIMAG", "DCONJG", "DABS", "DSIGN",
+			"DMOD", "DMAX1", "DMIN1", "DSQRT", "DEXP", "DLOG", "DLOG10",
+			"DSIN", "DCOS", "DTAN", "DASIN", "DACOS", "DATAN", "DATAN2",
+			"DSINH", "DCOSH", "DTANH":
+			return false, true
+		}
+		// generic intrinsic function
+		for _, a := range v.Args {
+			s, d := p.precision(a)
+			single, double = single || s, double || d
+		}
+	}
+	return
+}
+
+// characterItems change character literals with one character in
+// output list from rune to byte, because rune is same as INTEGER*4.
+// Example:
+//  WRITE ( * , * ) 'A'
+// Go code:
+//  intrinsic.WRITE(6, []byte("*"), byte('A'))
+func characterItems(call *goast.CallExpr) {
+	for i := 2; i < len(call.Args); i++ {
+		call.Args[i] = characterByte(call.Args[i])
+	}
+}
+
+// characterByte return byte for character literal with one character
+func characterByte(expr goast.Expr) goast.Expr {
+	lit, ok := expr.(*goast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return expr
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil || len(s) != 1 {
+		return expr
+	}
+	return goast.NewIdent(fmt.Sprintf("byte(%s)", strconv.QuoteRune(rune(s[0]))))
+}
+
 func (p *parser) getLineByLabel(label []byte) (fs []node) {
 
 	// memorization of FORMAT lines
@@ -160,104 +696,23 @@ func (p *parser) getLineByLabel(label []byte) (fs []node) {
 	return
 }
 
-func (p *parser) parseFormat(in []node) (s string) {
-	var fs []node
-	fs = append(fs, in...)
-	if len(fs) == 0 {
-		s = "\"\\n\""
-		return
-	}
-	// From:
-	//  ... / ...
-	// To:
-	//  ... , "\\n" , ...
-	for i := 0; i < len(fs); i++ {
-		if fs[i].tok != token.QUO { // not /
+// formatText return text of format specification from nodes of
+// statement FORMAT. Quotes inside of strings are written twice.
+// Example:
+//  100 FORMAT ( 'A = ' , 3 ( I5 , 2X ) )
+// Text:
+//  ('A = ',3(I5,2X))
+func formatText(ns []node) string {
+	var buf bytes.Buffer
+	for _, n := range ns {
+		if n.tok == token.STRING {
+			s := string(n.b[1 : len(n.b)-1])
+			buf.WriteString("'" + strings.Replace(s, "'", "''", -1) + "'")
 			continue
 		}
-		fs = append(fs[:i], append([]node{
-			{tok: token.COMMA, b: []byte(",")},
-			{tok: token.STRING, b: []byte("\\n")},
-			{tok: token.COMMA, b: []byte(",")},
-		}, fs[i+1:]...)...)
-	}
-
-	for i := 0; i < len(fs); i++ {
-		f := fs[i]
-		switch f.tok {
-		case token.IDENT, token.COMMENT:
-			switch byte(unicode.ToUpper(rune(f.b[0]))) {
-			case 'I':
-				s += "%" + string(f.b[1:]) + "d"
-			case 'F', 'G', 'P':
-				s += "%" + string(f.b[1:])
-				if i+1 < len(fs) && fs[i+1].tok == token.PERIOD {
-					i += 1
-					s += "."
-					if i+1 < len(fs) && fs[i+1].tok == token.INT {
-						s += string(fs[i+1].b)
-						i += 1
-					}
-				}
-				s += "f"
-			case 'E', 'D':
-				s += "%" + string(f.b[1:])
-				if i+1 < len(fs) && fs[i+1].tok == token.PERIOD {
-					i += 1
-					s += "."
-					if i+1 < len(fs) && fs[i+1].tok == token.INT {
-						s += string(fs[i+1].b)
-						i += 1
-					}
-				}
-				s += "E"
-			case 'A':
-				if string(f.b) == "A1" {
-					s += "%c"
-				} else {
-					if len(f.b) > 1 {
-						s += "%" + string(f.b[1:]) + "s"
-					} else {
-						s += "%s"
-					}
-				}
-
-			case 'L':
-				v, _ := strconv.Atoi(string(f.b[1:]))
-				for i := 0; i < v; i++ {
-					s += " "
-				}
-				s += "%t"
-
-			default:
-				p.addError(fmt.Sprintf(
-					"Not support format pos = %v: %s", in[0].pos, f))
-			}
-		case token.INT:
-			// 1X
-			// 5X
-			v, _ := strconv.Atoi(string(f.b))
-			if byte(unicode.ToUpper(rune(fs[i+1].b[0]))) == 'X' {
-				for i := 0; i < v; i++ {
-					s += " "
-				}
-				i++
-			}
-
-		case token.STRING:
-			str := string(f.b)
-			if str[0] == '"' || str[0] == '\'' {
-				s += str[1 : len(str)-1]
-			} else {
-				s += str
-			}
-		case token.COMMA, token.LPAREN, token.RPAREN:
-			// ignore
-		default:
-			s += "%v"
-		}
+		buf.Write(n.b)
 	}
-	return "\"" + s + "\\n\""
+	return buf.String()
 }
 
 // Example:
@@ -270,14 +725,102 @@ func (p *parser) parseRead() (stmts []goast.Stmt) {
 
 	stmts = p.parseStmt()
 
-	if e, ok := stmts[0].(*goast.ExprStmt); ok {
-		if c, ok := e.X.(*goast.CallExpr); ok {
+	// statement is call or call with branches to labels
+	renamed := false
+	goast.Inspect(stmts[0], func(n goast.Node) bool {
+		if c, ok := n.(*goast.CallExpr); ok && !renamed {
 			if sel, ok := c.Fun.(*goast.SelectorExpr); ok {
-				sel.Sel.Name = "READ"
+				sel.Sel.Name = strings.Replace(sel.Sel.Name, "WRITE", "READ", 1)
+				renamed = true
 			}
 		}
+		return !renamed
+	})
+
+	return
+}
+
+// Example:
+//  NAMELIST / PARAMS / DT , NSTEPS , TOL
+//  NAMELIST / IN / A , B / OUT / C
+func (p *parser) parseNamelist() {
+	p.expect(ftNamelist)
+	p.ident++
+
+	var group string
+	for ; p.ident < len(p.ns) && p.ns[p.ident].tok != ftNewLine; p.ident++ {
+		switch p.ns[p.ident].tok {
+		case token.QUO:
+			p.ident++
+			p.expect(token.IDENT)
+			group = strings.ToUpper(string(p.ns[p.ident].b))
+			p.ident++
+			p.expect(token.QUO)
+		case token.IDENT:
+			if group == "" {
+				panic(fmt.Errorf("NAMELIST without group name: %s", p.getLine()))
+			}
+			p.namelists[group] = append(p.namelists[group],
+				strings.ToUpper(string(p.ns[p.ident].b)))
+		case token.COMMA:
+			// ignore
+		default:
+			panic(fmt.Errorf("Not valid NAMELIST: %s", p.getLine()))
+		}
 	}
+	p.expect(ftNewLine)
+}
 
+// namelistGroup return name of NAMELIST group from argument
+// of input/output statement.
+// Example:
+//  NML = PARAMS
+//  PARAMS
+func (p *parser) namelistGroup(arg []node) (group string, ok bool) {
+	switch {
+	case len(arg) == 3 && arg[1].tok == token.ASSIGN &&
+		strings.ToUpper(string(arg[0].b)) == "NML":
+		group = strings.ToUpper(string(arg[2].b))
+		if _, ok = p.namelists[group]; !ok {
+			panic(fmt.Errorf("NAMELIST group %s is not found", group))
+		}
+	case len(arg) == 1 && arg[0].tok == token.IDENT:
+		group = strings.ToUpper(string(arg[0].b))
+		_, ok = p.namelists[group]
+	}
+	return
+}
+
+// parseNamelistIO return input/output of NAMELIST group.
+// Example:
+//  WRITE ( 6 , NML = PARAMS )
+// Go code:
+//  intrinsic.WRITENML(6, "PARAMS", "DT", DT, "NSTEPS", NSTEPS)
+func (p *parser) parseNamelistIO(unit, group string) (stmts []goast.Stmt) {
+	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+
+	args := []goast.Expr{
+		p.parseExprNodes(scan([]byte(unit))),
+		goast.NewIdent(strconv.Quote(group)),
+	}
+	for _, name := range p.namelists[group] {
+		if !p.isVariable(name) {
+			p.addError(fmt.Sprintf("Variable %s of NAMELIST group %s is not found",
+				name, group))
+			continue
+		}
+		args = append(args,
+			goast.NewIdent(strconv.Quote(name)),
+			p.addressOf([]node{{tok: token.IDENT, b: []byte(name)}}))
+	}
+
+	stmts = append(stmts, &goast.ExprStmt{X: &goast.CallExpr{
+		Fun: &goast.SelectorExpr{
+			X:   goast.NewIdent("intrinsic"),
+			Sel: goast.NewIdent("WRITENML"),
+		},
+		Args: args,
+	}})
 	return
 }
 
@@ -285,6 +828,7 @@ func (p *parser) parseRead() (stmts []goast.Stmt) {
 //  OPEN ( NTRA , FILE = SNAPS )
 //  OPEN ( NOUT , FILE = SUMMRY , STATUS = 'UNKNOWN' )
 //  OPEN ( UNIT = 2 , FILE = "./testdata/main.f" )
+//  OPEN ( 10 , STATUS = 'SCRATCH' , IOSTAT = IOS , ERR = 100 )
 func (p *parser) parseOpen() (stmts []goast.Stmt) {
 	p.expect(ftOpen)
 	p.ident++
@@ -292,38 +836,17 @@ func (p *parser) parseOpen() (stmts []goast.Stmt) {
 	args, end := separateArgsParen(p.ns[p.ident:])
 	p.ident += end
 
-	// Pattern:
-	//  OPEN( UNIT = ..., FILE = ...)
-	// Other parameters are ignored
-
-	// Part : UNIT
-	unit := string(args[0][0].b)
-	if len(args[0]) == 3 {
-		unit = string(args[0][2].b)
-	}
-
-	// Part : FILE
-	file := string(args[1][0].b)
-	if len(args[1]) == 3 {
-		file = string(args[1][2].b)
-	}
-
-	s := fmt.Sprintf("intrinsic.OPEN(%s,%s)", unit, file)
-	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
-	ast, err := goparser.ParseExpr(s)
-	if err != nil {
-		panic(fmt.Errorf("%s:%s", s, err))
-	}
-	stmts = append(stmts, &goast.ExprStmt{
-		X: ast,
-	})
-
-	return
+	specifiers, labels := p.ioSpecifiers("OPEN", args,
+		[]string{"UNIT", "FILE", "STATUS", "ACCESS", "FORM", "ACTION",
+			"POSITION", "RECL", "BLANK", "DELIM", "PAD", "CONVERT"},
+		nil)
+	return append(stmts, p.ioCall("OPEN", specifiers, labels))
 }
 
 // Example:
 //  CLOSE ( 2 )
 //  CLOSE ( NIN )
+//  CLOSE ( UNIT = 10 , STATUS = 'DELETE' )
 func (p *parser) parseClose() (stmts []goast.Stmt) {
 	p.expect(ftClose)
 	p.ident++
@@ -331,17 +854,9 @@ func (p *parser) parseClose() (stmts []goast.Stmt) {
 	args, end := separateArgsParen(p.ns[p.ident:])
 	p.ident += end
 
-	s := fmt.Sprintf("intrinsic.CLOSE(%s)", string(args[0][0].b))
-	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
-	ast, err := goparser.ParseExpr(s)
-	if err != nil {
-		panic(err)
-	}
-	stmts = append(stmts, &goast.ExprStmt{
-		X: ast,
-	})
-
-	return
+	specifiers, labels := p.ioSpecifiers("CLOSE", args,
+		[]string{"UNIT", "STATUS"}, nil)
+	return append(stmts, p.ioCall("CLOSE", specifiers, labels))
 }
 
 //  READ  ( NIN , FMT = * ) ( IDIM ( I ) , I = 1 , NIDIM )
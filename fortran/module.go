@@ -0,0 +1,540 @@
+package fortran
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+)
+
+// module is interface of MODULE for statement USE
+type module struct {
+	name     string
+	entities []entity // public entities of module
+
+	consts  map[string]goast.Expr // values of Go constants
+	access  map[string]bool       // true for private entity
+	private bool                  // default access is PRIVATE
+}
+
+// entity is variable, named constant or procedure of module
+type entity struct {
+	name      string // Fortran name
+	goName    string // name of Go declaration
+	typ       goType
+	value     []node // value of named constant
+	procedure bool
+	specifics []specific // specific procedures of generic interface
+}
+
+func (m *module) isPrivate(name string) bool {
+	if private, ok := m.access[name]; ok {
+		return private
+	}
+	return m.private
+}
+
+func (m *module) entity(name string) (e entity, ok bool) {
+	for _, e = range m.entities {
+		if e.name == name {
+			return e, true
+		}
+	}
+	return entity{}, false
+}
+
+// unexported return name of private entity of module with prefix of
+// module name, so private entities of different modules are not same.
+// Example:
+//  COUNTER of module SHAPES -> shapes_counter
+func (m *module) unexported(name string) string {
+	return strings.ToLower(m.name) + "_" + strings.ToLower(name)
+}
+
+// go/ast Visitor for rename identifiers of module entities
+type renamer map[string]string
+
+func (r renamer) Visit(node goast.Node) (w goast.Visitor) {
+	switch n := node.(type) {
+	case *goast.SelectorExpr:
+		// Example: intrinsic.SIZE
+		goast.Walk(r, n.X)
+		return nil
+	case *goast.Ident:
+		if to, ok := r[n.Name]; ok {
+			n.Name = to
+		}
+	}
+	return r
+}
+
+// parseModule parse MODULE with specification part and module procedures.
+// Variables of module are package-level Go variables, named constants
+// with constant expression are Go constants, module procedures are
+// ordinary Go functions. Private entities have unexported names.
+// Example:
+//  MODULE SHAPES
+//  REAL * 8 , PARAMETER :: PI = 3.14159D0
+//  INTEGER :: COUNTER = 0
+//  PRIVATE :: COUNTER
+//  CONTAINS
+//  ...
+//  END MODULE SHAPES
+// Go code:
+//  const PI float64 = 3.14159e0
+//  var shapes_counter = new(int)
+//  func init() {
+//  	(*shapes_counter) = 0
+//  }
+func (p *parser) parseModule() (decls []goast.Decl) {
+	if Debug {
+		fmt.Fprintf(os.Stdout, "Parse module\n")
+	}
+	p.expect(ftModule)
+	p.ident++
+	p.expect(token.IDENT)
+	m := &module{
+		name:   strings.ToUpper(string(p.ns[p.ident].b)),
+		consts: map[string]goast.Expr{},
+		access: map[string]bool{},
+	}
+	p.gotoEndLine()
+
+	// specification part
+	p.module = m
+	spec := p.parseListStmt()
+	p.module = nil
+	generics := p.generics
+
+	var (
+		consts = &goast.GenDecl{Tok: token.CONST}
+		vars   = &goast.GenDecl{Tok: token.VAR}
+		init   []goast.Stmt
+		host   []entity
+	)
+	for _, v := range p.initVars {
+		if v.typ.module {
+			// imported by USE
+			continue
+		}
+		e := entity{name: v.name, goName: v.name, typ: v.typ, value: p.constants[v.name]}
+		e.typ.module = true
+		if v.typ.allocatable {
+			// lower bounds are package-level
+			p.lbounds[v.name] = true
+		}
+		if m.isPrivate(v.name) {
+			e.goName = m.unexported(v.name)
+			p.renames[v.name] = e.goName
+			if v.typ.allocatable {
+				p.renames[v.name+lboundPostfix] = e.goName + lboundPostfix
+			}
+		} else {
+			m.entities = append(m.entities, e)
+		}
+		host = append(host, e)
+		if v.typ.constant {
+			consts.Specs = append(consts.Specs, &goast.ValueSpec{
+				Names:  []*goast.Ident{goast.NewIdent(v.name)},
+				Type:   goast.NewIdent(v.typ.String()),
+				Values: []goast.Expr{m.consts[v.name]},
+			})
+		}
+	}
+	for _, s := range append(p.initializeVars(), spec...) {
+		if a, ok := s.(*goast.AssignStmt); ok && a.Tok == token.DEFINE && len(a.Lhs) == 1 {
+			vars.Specs = append(vars.Specs, &goast.ValueSpec{
+				Names:  []*goast.Ident{a.Lhs[0].(*goast.Ident)},
+				Values: a.Rhs,
+			})
+			continue
+		}
+		init = append(init, s)
+	}
+
+	// entities imported by USE are public by default
+	imports := p.imports
+	for _, e := range imports {
+		if !m.isPrivate(e.name) {
+			m.entities = append(m.entities, e)
+		}
+	}
+	host = append(host, imports...)
+
+	var specDecls []goast.Decl
+	if len(consts.Specs) > 0 {
+		specDecls = append(specDecls, consts)
+	}
+	if len(vars.Specs) > 0 {
+		specDecls = append(specDecls, vars)
+	}
+	if hasCode(init) {
+		fd := &goast.FuncDecl{
+			Name: goast.NewIdent("init"),
+			Type: &goast.FuncType{Params: &goast.FieldList{}},
+			Body: &goast.BlockStmt{Lbrace: 1, List: init},
+		}
+		goast.Walk(callArg{p: p}, fd.Body)
+		goast.Walk(intrinsic{p: p}, fd.Body)
+		goast.Walk(valueArgs{p: p}, fd.Body)
+		goast.Walk(callArgumentSimplification{}, fd.Body)
+		specDecls = append(specDecls, fd)
+	}
+	for _, d := range specDecls {
+		goast.Walk(renamer(p.renames), d)
+	}
+	if len(specDecls) > 0 && len(p.comments) > 0 {
+		doc := &goast.CommentGroup{}
+		for _, c := range p.comments {
+			doc.List = append(doc.List, &goast.Comment{Text: c})
+		}
+		switch d := specDecls[0].(type) {
+		case *goast.GenDecl:
+			d.Doc = doc
+		case *goast.FuncDecl:
+			d.Doc = doc
+		}
+		p.comments = []string{}
+	}
+	decls = append(decls, specDecls...)
+
+	// module procedures
+	var (
+		functions = p.functionExternalName
+		renames   = p.renames
+		private   = renamer{}
+	)
+	if p.ident < len(p.ns) && p.ns[p.ident].tok == ftContains {
+		p.gotoEndLine()
+		p.contained = true
+		defer func() {
+			p.contained = false
+		}()
+	procedures:
+		for ; p.ident < len(p.ns); p.ident++ {
+			switch p.ns[p.ident].tok {
+			case ftNewLine:
+				continue
+			case token.COMMENT:
+				p.comments = append(p.comments, "//"+string(p.ns[p.ident].b))
+				continue
+			case ftEnd:
+				p.gotoEndLine()
+				break procedures
+			}
+
+			// host association with entities of module
+			p.init()
+			p.functionExternalName = append(p.functionExternalName, functions...)
+			for k, v := range renames {
+				p.renames[k] = v
+			}
+			for _, e := range host {
+				p.useEntity(e.name, e)
+			}
+			for k, v := range generics {
+				p.generics[k] = v
+			}
+
+			decl := p.parseProcedure()
+			fd, ok := decl.(*goast.FuncDecl)
+			if !ok {
+				p.addError("Cannot parse module procedure : " + p.getLine())
+				p.gotoEndLine()
+				continue
+			}
+			name := fd.Name.Name
+			if m.isPrivate(name) {
+				private[name] = m.unexported(name)
+			} else {
+				m.entities = append(m.entities, entity{name: name, goName: name, procedure: true})
+			}
+			decls = append(decls, decl)
+		}
+	}
+	if len(private) > 0 {
+		for _, d := range decls {
+			goast.Walk(private, d)
+		}
+	}
+
+	// generic interfaces of module
+	var names []string
+	for name := range generics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+generic:
+	for _, name := range names {
+		for _, e := range imports {
+			if e.name == name {
+				// imported by USE
+				continue generic
+			}
+		}
+		if m.isPrivate(name) {
+			continue
+		}
+		e := entity{name: name, goName: name}
+		for _, s := range generics[name] {
+			if to, ok := private[s.name]; ok {
+				s.name = to
+			}
+			e.specifics = append(e.specifics, s)
+		}
+		m.entities = append(m.entities, e)
+	}
+
+	p.modules[m.name] = m
+	return
+}
+
+// hasCode return true if statements is not only comments
+func hasCode(stmts []goast.Stmt) bool {
+	for _, s := range stmts {
+		if e, ok := s.(*goast.ExprStmt); ok {
+			if id, ok := e.X.(*goast.Ident); ok && strings.HasPrefix(id.Name, "//") {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// moduleConst add named constant of module as Go constant, if
+// value is constant expression.
+// Example:
+//  REAL * 8 , PARAMETER :: TWOPI = 2 * PI
+// Go code:
+//  const TWOPI float64 = 2 * PI
+func (p *parser) moduleConst(name string, value []node) bool {
+	if p.module == nil {
+		return false
+	}
+	v, ok := p.initVars.get(name)
+	if !ok || v.typ.isArray() || v.typ.baseType == "byte" {
+		return false
+	}
+	expr := p.parseExprNodes(value)
+	if !p.isConstExpr(expr) {
+		return false
+	}
+	v.typ.constant = true
+	p.initVars.set(name, v.typ)
+	p.module.consts[v.name] = expr
+	return true
+}
+
+// isConstExpr return true for Go constant expression
+func (p *parser) isConstExpr(expr goast.Expr) bool {
+	switch e := expr.(type) {
+	case *goast.BasicLit:
+		return e.Kind != token.STRING
+	case *goast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return true
+		}
+		v, ok := p.initVars.get(e.Name)
+		return ok && v.typ.constant
+	case *goast.ParenExpr:
+		return p.isConstExpr(e.X)
+	case *goast.UnaryExpr:
+		return p.isConstExpr(e.X)
+	case *goast.BinaryExpr:
+		return p.isConstExpr(e.X) && p.isConstExpr(e.Y)
+	}
+	return false
+}
+
+// useEntity add entity of module in scope with local name
+func (p *parser) useEntity(local string, e entity) {
+	if e.specifics != nil {
+		e.name = local
+		p.imports = append(p.imports, e)
+		p.generics[local] = append(p.generics[local], e.specifics...)
+		return
+	}
+	if local != e.goName {
+		p.renames[local] = e.goName
+		if e.typ.allocatable {
+			p.renames[local+lboundPostfix] = e.goName + lboundPostfix
+		}
+	}
+	e.name = local
+	p.imports = append(p.imports, e)
+	if e.procedure {
+		p.functionExternalName = append(p.functionExternalName, local)
+		return
+	}
+	p.initVars.add(local, e.typ)
+	if e.value != nil {
+		p.constants[local] = e.value
+	}
+}
+
+// parseUse parse statement USE with ONLY list and renames.
+// Examples:
+//  USE SHAPES
+//  USE SHAPES , ONLY : PI , CIRCLE => AREA
+//  USE SHAPES , R => RADIUS
+func (p *parser) parseUse() {
+	p.expect(ftUse)
+	p.ident++
+	p.expect(token.IDENT)
+	name := strings.ToUpper(string(p.ns[p.ident].b))
+	p.ident++
+	start := p.ident
+	p.gotoEndLine()
+	list := p.ns[start:p.ident]
+
+	m, ok := p.findModule(name)
+	if !ok {
+		p.addError("Cannot find module : " + name)
+		return
+	}
+
+	var only bool
+	if len(list) > 0 && list[0].tok == token.COMMA {
+		list = list[1:]
+	}
+	if len(list) > 1 && list[0].tok == token.IDENT &&
+		strings.ToUpper(string(list[0].b)) == "ONLY" && list[1].tok == token.COLON {
+		only = true
+		list = list[2:]
+	}
+
+	renamed := map[string]bool{}
+	if len(list) > 0 {
+		items, _ := separateArgsParen(append(append([]node{nodeLParen}, list...), nodeRParen))
+		for _, item := range items {
+			local := strings.ToUpper(string(item[0].b))
+			remote := local
+			if name, ok := genericName(item); ok {
+				// Example:
+				//  OPERATOR ( .CROSS. )
+				local, remote = name, name
+			} else if len(item) == 4 && item[1].tok == token.ASSIGN && item[2].tok == token.GTR {
+				// rename
+				// Example:
+				//  CIRCLE => AREA
+				remote = strings.ToUpper(string(item[3].b))
+			} else if len(item) != 1 {
+				p.addError("Not support USE item : " + nodesToString(item))
+				continue
+			}
+			e, ok := m.entity(remote)
+			if !ok {
+				p.addError(fmt.Sprintf("Cannot find %s in module %s", remote, name))
+				continue
+			}
+			renamed[remote] = true
+			p.useEntity(local, e)
+		}
+	}
+	if only {
+		return
+	}
+	for _, e := range m.entities {
+		if !renamed[e.name] {
+			p.useEntity(e.name, e)
+		}
+	}
+}
+
+// parseAccess parse statements PUBLIC and PRIVATE of module.
+// Examples:
+//  PRIVATE
+//  PUBLIC :: PI , AREA , OPERATOR ( .CROSS. )
+func (p *parser) parseAccess() {
+	private := strings.ToUpper(string(p.ns[p.ident].b)) == "PRIVATE"
+	p.ident++
+	if p.ns[p.ident].tok == ftDoubleColon {
+		p.ident++
+	}
+	start := p.ident
+	p.gotoEndLine()
+	if p.module == nil {
+		p.addError("Statement is not in MODULE : " + p.getLine())
+		return
+	}
+	if start == p.ident {
+		p.module.private = private
+		return
+	}
+	list := append([]node{nodeLParen}, p.ns[start:p.ident]...)
+	items, _ := separateArgsParen(append(list, nodeRParen))
+	for _, item := range items {
+		name, ok := genericName(item)
+		if !ok {
+			p.addError("Not support access item : " + nodesToString(item))
+			continue
+		}
+		p.module.access[name] = private
+	}
+}
+
+// isAccess return true for statements PUBLIC and PRIVATE
+func (p *parser) isAccess() bool {
+	if p.ns[p.ident].tok != token.IDENT || p.ident+1 >= len(p.ns) {
+		return false
+	}
+	switch strings.ToUpper(string(p.ns[p.ident].b)) {
+	case "PUBLIC", "PRIVATE":
+	default:
+		return false
+	}
+	switch p.ns[p.ident+1].tok {
+	case ftNewLine, ftDoubleColon, token.IDENT:
+		return true
+	}
+	return false
+}
+
+// findModule return module parsed before or module from other sources
+func (p *parser) findModule(name string) (m *module, ok bool) {
+	if m, ok = p.modules[name]; ok {
+		return
+	}
+	for i, src := range p.sources {
+		if src == nil || !bytes.Contains(bytes.ToUpper(src), []byte(name)) {
+			continue
+		}
+		q := parser{
+			pkgs:    map[string]bool{},
+			modules: map[string]*module{},
+			sources: append([][]byte{}, p.sources...),
+		}
+		q.sources[i] = nil
+		q.ns = scan(src)
+		found := false
+		for j := 0; j+1 < len(q.ns); j++ {
+			if q.ns[j].tok == ftModule &&
+				strings.ToUpper(string(q.ns[j+1].b)) == name {
+				found = true
+			}
+		}
+		if !found {
+			continue
+		}
+		p.sources[i] = nil
+		q.parseNodes()
+		for k, v := range q.modules {
+			if _, ok := p.modules[k]; !ok {
+				p.modules[k] = v
+			}
+		}
+		for k, v := range q.signatures {
+			if _, ok := p.signatures[k]; !ok {
+				p.signatures[k] = v
+			}
+		}
+		if m, ok = p.modules[name]; ok {
+			return
+		}
+	}
+	return
+}
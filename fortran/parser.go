@@ -42,11 +42,26 @@ func (v *varInits) del(n string) {
 }
 
 func (v *varInits) add(name string, typ goType) {
+	if old, ok := v.get(name); ok && (old.typ.module || old.typ.host) {
+		// local variable hides entity of module or host
+		v.del(name)
+	}
 	vs := []varInitialization(*v)
 	vs = append(vs, varInitialization{name: strings.ToUpper(name), typ: typ})
 	*v = varInits(vs)
 }
 
+// set change type of variable
+func (v *varInits) set(n string, typ goType) {
+	n = strings.ToUpper(n)
+	for i := range *v {
+		if (*v)[i].name == n {
+			(*v)[i].typ = typ
+			return
+		}
+	}
+}
+
 func (p parser) getSize(name string, col int) (size int, ok bool) {
 	v, ok := p.initVars.get(name)
 	if !ok {
@@ -111,6 +126,12 @@ func (p parser) getArrayBegin(name string, col int) int {
 		// [[INT, `99`] [:, `:`] [INT, `101`]]
 		if n.tok == token.COLON {
 			strBegin := strings.Replace(nodesToString(v.typ.arrayNode[col][:i]), " ", "", -1)
+			if strBegin == "" {
+				// deferred shape of allocatable array
+				// Example:
+				//  REAL , ALLOCATABLE :: A ( : )
+				return 1
+			}
 			b, err := strconv.Atoi(strBegin)
 			if err != nil {
 				p.addError("Cannot parse begin value: " + strBegin)
@@ -181,15 +202,39 @@ type parser struct {
 
 	pkgs        map[string]bool // import packages
 	endLabelDo  map[string]int  // label of DO
+	loops       []doLoop        // stack of DO loops
+	loopLabels  int             // amount of Go labels for loops
+	selectDepth int             // amount of SELECT CASE around statement
+	where       int             // branch of WHERE construct around statement
 	allLabels   map[string]bool // list of all labels
 	foundLabels map[string]bool // list labels found in source
 
+	arrayIndexes map[string]bool // Go-local indexes of array expressions
+	lbounds      map[string]bool // allocatable arrays with used lower bounds
+	saves        map[string]bool // initialized variables with attribute SAVE
+	saved        []goast.Decl    // package-level storage of saved variables
+	procedure    string          // Go name of parsed procedure
+
 	parameters map[string]string // constants
 
 	formats map[string][]node // source line with command FORMAT
 
 	constants map[string][]node
 
+	modules map[string]*module // parsed modules
+	sources [][]byte           // sources with other modules
+	module  *module            // specification part of module
+	imports []entity           // entities of modules in scope
+	renames map[string]string  // Go names of entities of modules
+
+	signatures map[string]signature  // interfaces of all procedures
+	generics   map[string][]specific // generic interfaces in scope
+	results    map[string]string     // variables of function result
+	elementals map[string]bool       // ELEMENTAL functions
+	isFunction bool                  // parse FUNCTION
+	contained  bool                  // parse module or internal procedure
+	namelists  map[string][]string   // NAMELIST groups
+
 	errs []error
 }
 
@@ -200,6 +245,14 @@ func (p *parser) addImport(pkg string) {
 func (p *parser) init() {
 	p.functionExternalName = make([]string, 0)
 	p.endLabelDo = map[string]int{}
+	p.loops = nil
+	p.loopLabels = 0
+	p.selectDepth = 0
+	p.where = 0
+	p.arrayIndexes = map[string]bool{}
+	p.lbounds = map[string]bool{}
+	p.saves = map[string]bool{}
+	p.procedure = ""
 	p.allLabels = map[string]bool{}
 	p.foundLabels = map[string]bool{}
 	p.initVars = varInits{}
@@ -207,6 +260,10 @@ func (p *parser) init() {
 	p.formats = map[string][]node{}
 	p.implicit = nil
 	p.constants = map[string][]node{}
+	p.imports = nil
+	p.renames = map[string]string{}
+	p.generics = map[string][]specific{}
+	p.namelists = map[string][]string{}
 }
 
 // list view - only for debugging
@@ -226,8 +283,9 @@ func lv(ns []node) (output string) {
 	return
 }
 
-// Parse is convert fortran source to go ast tree
-func Parse(b []byte, packageName string) (_ goast.File, errs []error) {
+// Parse is convert fortran source to go ast tree.
+// Sources of other files are used for find modules of USE statements.
+func Parse(b []byte, packageName string, modules ...[]byte) (_ goast.File, errs []error) {
 
 	if packageName == "" {
 		packageName = "main"
@@ -239,6 +297,9 @@ func Parse(b []byte, packageName string) (_ goast.File, errs []error) {
 		p.pkgs = map[string]bool{}
 	}
 
+	p.modules = map[string]*module{}
+	p.sources = append([][]byte{}, modules...)
+
 	p.ns = scan(b)
 
 	p.ast.Name = goast.NewIdent(packageName)
@@ -246,6 +307,7 @@ func Parse(b []byte, packageName string) (_ goast.File, errs []error) {
 	var decls []goast.Decl
 	p.ident = 0
 	decls = p.parseNodes()
+	p.ioRuntime(decls)
 
 	// add packages
 	for pkg := range p.pkgs {
@@ -336,6 +398,7 @@ func Parse(b []byte, packageName string) (_ goast.File, errs []error) {
 		})
 	}
 
+	p.ast.Decls = append(p.ast.Decls, p.saved...)
 	p.ast.Decls = append(p.ast.Decls, decls...)
 
 	strC := strChanger{}
@@ -404,6 +467,9 @@ func (p *parser) parseNodes() (decls []goast.Decl) {
 		return
 	}
 
+	p.procedureHeaders()
+	p.collectSignatures()
+
 	// find all names of FUNCTION, SUBROUTINE, PROGRAM
 	var internalFunction []string
 	for ; p.ident < len(p.ns); p.ident++ {
@@ -422,13 +488,6 @@ func (p *parser) parseNodes() (decls []goast.Decl) {
 			continue
 		}
 
-		// Example:
-		//   RECURSIVE SUBROUTINE CGELQT3( M, N, A, LDA, T, LDT, INFO )
-		if strings.ToUpper(string(p.ns[p.ident].b)) == "RECURSIVE" {
-			p.ns[p.ident].tok, p.ns[p.ident].b = ftNewLine, []byte("\n")
-			continue
-		}
-
 		// FUNCTION
 		for i := p.ident; i < len(p.ns) && p.ns[i].tok != ftNewLine; i++ {
 			if p.ns[p.ident].tok == ftFunction {
@@ -469,6 +528,9 @@ func (p *parser) parseNodes() (decls []goast.Decl) {
 			decl = p.parseProgram()
 			decls = append(decls, decl)
 			next = true
+		case ftModule: // MODULE
+			decls = append(decls, p.parseModule()...)
+			next = true
 		default:
 			// Example :
 			//  COMPLEX FUNCTION CDOTU ( N , CX , INCX , CY , INCY )
@@ -597,6 +659,91 @@ func (r replacer) Visit(node goast.Node) (w goast.Visitor) {
 	return r
 }
 
+// saveVar return initialization of variable with implicit attribute
+// SAVE. Variable is saved in package-level variable between calls of
+// procedure and initialized only once.
+// Example:
+//  SUBROUTINE COUNT
+//  INTEGER :: NCALL = 0
+// Go code:
+//  var COUNT_NCALL *int
+//  ...
+//  if COUNT_NCALL == nil {
+//  	COUNT_NCALL = NCALL
+//  	(*NCALL) = 0
+//  }
+//  NCALL = COUNT_NCALL
+func (p *parser) saveVar(name string, init []goast.Stmt) []goast.Stmt {
+	v, _ := p.initVars.get(name)
+	p.saves[v.name] = true
+	save := p.procedure + "_" + v.name
+	return []goast.Stmt{
+		&goast.IfStmt{
+			Cond: &goast.BinaryExpr{
+				X:  goast.NewIdent(save),
+				Op: token.EQL,
+				Y:  goast.NewIdent("nil"),
+			},
+			Body: &goast.BlockStmt{List: append([]goast.Stmt{
+				&goast.AssignStmt{
+					Lhs: []goast.Expr{goast.NewIdent(save)},
+					Tok: token.ASSIGN,
+					Rhs: []goast.Expr{goast.NewIdent(v.name)},
+				},
+			}, init...)},
+		},
+		&goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent(v.name)},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{goast.NewIdent(save)},
+		},
+	}
+}
+
+// saveDecl add package-level declaration for saved variable by
+// declaration of local variable.
+// Example:
+//  NCALL := new(int)
+// Go code:
+//  var COUNT_NCALL *int
+func (p *parser) saveDecl(s goast.Stmt) {
+	a, ok := s.(*goast.AssignStmt)
+	if !ok || a.Tok != token.DEFINE || len(a.Lhs) != 1 || len(a.Rhs) != 1 {
+		return
+	}
+	id, ok := a.Lhs[0].(*goast.Ident)
+	if !ok || !p.saves[id.Name] {
+		return
+	}
+	var typ goast.Expr
+	switch c := a.Rhs[0].(type) {
+	case *goast.CallExpr:
+		switch f := c.Fun.(type) {
+		case *goast.Ident:
+			// new(int)
+			if f.Name == "new" && len(c.Args) == 1 {
+				typ = &goast.StarExpr{X: c.Args[0]}
+			}
+		case *goast.FuncLit:
+			// func() *[]int {...}()
+			if r := f.Type.Results; r != nil && len(r.List) == 1 {
+				typ = r.List[0].Type
+			}
+		}
+	}
+	if typ == nil {
+		p.addError("Cannot find type of saved variable : " + id.Name)
+		return
+	}
+	p.saved = append(p.saved, &goast.GenDecl{
+		Tok: token.VAR,
+		Specs: []goast.Spec{&goast.ValueSpec{
+			Names: []*goast.Ident{goast.NewIdent(p.procedure + "_" + id.Name)},
+			Type:  typ,
+		}},
+	})
+}
+
 // init vars
 func (p *parser) initializeVars() (vars []goast.Stmt) {
 	defer func() {
@@ -613,6 +760,32 @@ func (p *parser) initializeVars() (vars []goast.Stmt) {
 		name := ([]varInitialization(p.initVars)[i]).name
 		assign := strings.Contains(name, "COMMON.") || strings.Contains(name, returnPostfix)
 		goT := ([]varInitialization(p.initVars)[i]).typ
+		if goT.module || goT.constant || goT.host {
+			// declared in module or host
+			continue
+		}
+		if goT.allocatable {
+			// allocatable array is not allocated
+			// Example:
+			//  A := new([][]float64)
+			tok := token.DEFINE
+			if assign {
+				tok = token.ASSIGN
+			}
+			vars = append(vars, &goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent(name)},
+				Tok: tok,
+				Rhs: []goast.Expr{&goast.CallExpr{
+					Fun: goast.NewIdent("new"),
+					Args: []goast.Expr{goast.NewIdent(
+						strings.Repeat("[]", len(goT.arrayNode)) + goT.getBaseType())},
+				}},
+			})
+			if p.lbounds[name] {
+				vars = append(vars, lowerBounds(name, goT))
+			}
+			continue
+		}
 		switch p.getArrayLen(name) {
 		case 0:
 
@@ -893,6 +1066,7 @@ func isIgnoreCall(call *goast.CallExpr) bool {
 	if id, ok := call.Fun.(*goast.Ident); ok {
 		switch id.Name {
 		case "append",
+			"make",
 			"panic":
 			return true
 		}
@@ -945,6 +1119,27 @@ func (c callArg) Visit(node goast.Node) goast.Visitor {
 			}
 
 		case *goast.Ident, *goast.IndexExpr, *goast.ParenExpr:
+			if id, ok := a.(*goast.Ident); ok {
+				if id.Name == "nil" {
+					// absent optional argument
+					continue
+				}
+				if id.Name == "true" || id.Name == "false" {
+					call.Args[i] = goast.NewIdent(fmt.Sprintf(
+						"func()*bool{y:=%s;return &y}()", id.Name))
+					continue
+				}
+				if v, ok := c.p.initVars.get(id.Name); ok && v.typ.constant {
+					// Go constant of module
+					name := id.Name
+					if to, ok := c.p.renames[name]; ok {
+						name = to
+					}
+					call.Args[i] = goast.NewIdent(fmt.Sprintf(
+						"func()*%s{y:=%s;return &y}()", v.typ.String(), name))
+					continue
+				}
+			}
 			// from:  NAME
 			// to  : &NAME
 			call.Args[i] = &goast.UnaryExpr{
@@ -965,6 +1160,82 @@ func (c callArg) Visit(node goast.Node) goast.Visitor {
 	return c
 }
 
+// procedureHeaders remove prefixes RECURSIVE, PURE, IMPURE, ELEMENTAL
+// and suffix RESULT from headers of procedures.
+// Go functions are recursive and local variables are not shared between
+// calls, so only ELEMENTAL functions are saved for array expressions.
+// Example:
+//  RECURSIVE INTEGER FUNCTION FACT ( N ) RESULT ( R )
+// To:
+//  INTEGER FUNCTION FACT ( N )
+func (p *parser) procedureHeaders() {
+	if p.results == nil {
+		p.results = map[string]string{}
+		p.elementals = map[string]bool{}
+	}
+	var ns []node
+	for start := 0; start < len(p.ns); {
+		end := start
+		for end < len(p.ns) && p.ns[end].tok != ftNewLine {
+			end++
+		}
+		if end < len(p.ns) {
+			end++
+		}
+		line := p.ns[start:end]
+		start = end
+
+		pos := -1
+		for i := 0; i+1 < len(line); i++ {
+			if (line[i].tok == ftSubroutine || line[i].tok == ftFunction) &&
+				line[i+1].tok == token.IDENT {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			ns = append(ns, line...)
+			continue
+		}
+
+		name := strings.ToUpper(string(line[pos+1].b))
+		for i := 0; i < len(line); i++ {
+			word := strings.ToUpper(string(line[i].b))
+			switch {
+			case i < pos && line[i].tok == token.IDENT &&
+				(word == "RECURSIVE" || word == "PURE" || word == "IMPURE"):
+				continue
+			case i < pos && line[i].tok == token.IDENT && word == "ELEMENTAL":
+				p.elementals[name] = true
+				continue
+			case i > pos+1 && line[i].tok == token.IDENT && word == "RESULT" &&
+				i+3 < len(line) && line[i+1].tok == token.LPAREN &&
+				line[i+3].tok == token.RPAREN:
+				p.results[name] = strings.ToUpper(string(line[i+2].b))
+				i += 3
+				continue
+			}
+			ns = append(ns, line[i])
+		}
+	}
+	p.ns = ns
+}
+
+// resultType return type of function result declared in body of
+// function or implicit type
+func (p *parser) resultType(name string) goType {
+	if v, ok := p.initVars.get(name); ok {
+		return v.typ
+	}
+	if typ, ok := p.isImplicit(name[0]); ok {
+		return parseType(typ)
+	}
+	if 'I' <= name[0] && name[0] <= 'N' {
+		return goType{baseType: "int"}
+	}
+	return goType{baseType: "float64", single: true}
+}
+
 // Example :
 //  COMPLEX FUNCTION CDOTU ( N , CX , INCX , CY , INCY )
 //  DOUBLE PRECISION FUNCTION DNRM2 ( N , X , INCX )
@@ -978,9 +1249,146 @@ func (p *parser) parseFunction() (decl goast.Decl) {
 			p.ns[i].tok = ftSubroutine
 		}
 	}
+	p.isFunction = true
 	return p.parseSubroutine()
 }
 
+// parseProcedure parse SUBROUTINE or FUNCTION.
+// Return nil, if it is not procedure.
+func (p *parser) parseProcedure() (decl goast.Decl) {
+	if p.ns[p.ident].tok == ftSubroutine {
+		return p.parseSubroutine()
+	}
+	for i := p.ident; i < len(p.ns) && p.ns[i].tok != ftNewLine; i++ {
+		if p.ns[i].tok == ftFunction {
+			return p.parseFunction()
+		}
+	}
+	return nil
+}
+
+// parseInternal parse internal procedures after statement CONTAINS.
+// Internal procedures are Go closures with access to variables of host.
+// Closures are declared before assignment for calls between internal
+// procedures.
+// Example:
+//  CONTAINS
+//  SUBROUTINE ADD ( K )
+//  INTEGER K
+//  TOTAL = TOTAL + K
+//  END SUBROUTINE
+// Go code:
+//  var ADD func(K *int)
+//  ADD = func(K *int) {
+//  	(*TOTAL) = (*TOTAL) + (*(K))
+//  }
+func (p *parser) parseInternal() (stmts []goast.Stmt) {
+	p.expect(ftContains)
+	p.gotoEndLine()
+
+	// state of host
+	host := *p
+	comments := p.comments
+	p.comments = nil
+	defer func() {
+		p.functionExternalName = host.functionExternalName
+		p.endLabelDo = host.endLabelDo
+		p.loops = host.loops
+		p.loopLabels = host.loopLabels
+		p.selectDepth = host.selectDepth
+		p.where = host.where
+		p.arrayIndexes = host.arrayIndexes
+		p.lbounds = host.lbounds
+		p.saves = host.saves
+		p.procedure = host.procedure
+		p.allLabels = host.allLabels
+		p.foundLabels = host.foundLabels
+		p.initVars = host.initVars
+		p.parameters = host.parameters
+		p.formats = host.formats
+		p.implicit = host.implicit
+		p.constants = host.constants
+		p.imports = host.imports
+		p.renames = host.renames
+		p.generics = host.generics
+		p.namelists = host.namelists
+		p.contained = host.contained
+		p.comments = comments
+	}()
+	p.contained = true
+
+	var procedures []*goast.FuncDecl
+	for ; p.ident < len(p.ns); p.ident++ {
+		switch p.ns[p.ident].tok {
+		case ftNewLine:
+			continue
+		case token.COMMENT:
+			p.comments = append(p.comments, "//"+string(p.ns[p.ident].b))
+			continue
+		}
+		if p.ns[p.ident].tok == ftEnd {
+			// end of host
+			p.gotoEndLine()
+			break
+		}
+
+		// host association
+		p.init()
+		p.lbounds = host.lbounds
+		p.procedure = host.procedure
+		p.functionExternalName = append(p.functionExternalName, host.functionExternalName...)
+		p.implicit = host.implicit
+		for k, v := range host.renames {
+			p.renames[k] = v
+		}
+		for k, v := range host.constants {
+			p.constants[k] = v
+		}
+		for k, v := range host.generics {
+			p.generics[k] = v
+		}
+		for k, v := range host.namelists {
+			p.namelists[k] = v
+		}
+		for _, v := range host.initVars {
+			typ := v.typ
+			typ.host = true
+			p.initVars.add(v.name, typ)
+		}
+
+		fd, ok := p.parseProcedure().(*goast.FuncDecl)
+		if !ok {
+			p.addError("Cannot parse internal procedure : " + p.getLine())
+			p.gotoEndLine()
+			continue
+		}
+		procedures = append(procedures, fd)
+	}
+
+	for _, fd := range procedures {
+		stmts = append(stmts, &goast.DeclStmt{Decl: &goast.GenDecl{
+			Tok: token.VAR,
+			Specs: []goast.Spec{&goast.ValueSpec{
+				Names: []*goast.Ident{goast.NewIdent(fd.Name.Name)},
+				Type:  fd.Type,
+			}},
+		}})
+	}
+	for _, fd := range procedures {
+		if fd.Doc != nil {
+			for _, c := range fd.Doc.List {
+				stmts = append(stmts, &goast.ExprStmt{X: goast.NewIdent(c.Text)})
+			}
+		}
+		stmts = append(stmts, &goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent(fd.Name.Name)},
+			Tok: token.ASSIGN,
+			Rhs: []goast.Expr{&goast.FuncLit{Type: fd.Type, Body: fd.Body}},
+		})
+	}
+	return
+}
+
 // Example:
 //   PROGRAM MAIN
 func (p *parser) parseProgram() (decl goast.Decl) {
@@ -998,6 +1406,8 @@ func (p *parser) parseProgram() (decl goast.Decl) {
 
 const returnPostfix string = "_RETURN"
 
+const alternatePostfix string = "_ALTERNATE"
+
 // parseSubroutine  is parsed SUBROUTINE, FUNCTION, PROGRAM
 // Example :
 //  SUBROUTINE CHBMV ( UPLO , N , K , ALPHA , A , LDA , X , INCX , BETA , Y , INCY )
@@ -1012,6 +1422,9 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 		p.init()
 	}()
 
+	isFunction := p.isFunction
+	p.isFunction = false
+
 	var fd goast.FuncDecl
 	fd.Type = &goast.FuncType{
 		Params: &goast.FieldList{},
@@ -1042,11 +1455,15 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 	if Debug {
 		fmt.Fprintf(os.Stdout, "subroutine name is : %s\n", name)
 	}
+	if p.procedure != "" {
+		// internal procedure
+		p.procedure += "_"
+	}
+	p.procedure += name
 
 	// Add return type is exist
 	returnName := name + returnPostfix
-	if len(returnType) > 0 {
-		typ := parseType(returnType)
+	addResult := func(typ goType) {
 		fd.Type.Results = &goast.FieldList{
 			List: []*goast.Field{
 				{
@@ -1057,24 +1474,82 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 		}
 		p.initVars.add(returnName, typ)
 	}
+	if len(returnType) > 0 {
+		addResult(parseType(returnType))
+	}
+
+	// variable of function result
+	// Example:
+	//  INTEGER FUNCTION FACT ( N ) RESULT ( R )
+	var result string
+	if isFunction {
+		result = name
+		if r, ok := p.results[name]; ok {
+			result = r
+		}
+	}
 	defer func() {
-		// change function name variable to returnName
-		if len(returnType) > 0 {
+		// change function result variable to returnName
+		if result != "" {
 			v := initVis()
-			v.c[name] = returnName
+			v.c[result] = returnName
 			goast.Walk(v, fd.Body)
 		}
 	}()
 
 	// Parameters
 	p.ident++
-	fd.Type.Params.List = p.parseParamDecl()
+	var alternateReturn bool
+	fd.Type.Params.List, alternateReturn = p.parseParamDecl()
+
+	// Subroutine with alternate returns
+	// Example:
+	//  SUBROUTINE SOLVE(A, N, *)
+	// Go function returns index of alternate return:
+	//  func SOLVE(A *float64, N *int) (SOLVE_ALTERNATE int)
+	if alternateReturn && len(returnType) == 0 {
+		fd.Type.Results = &goast.FieldList{
+			List: []*goast.Field{
+				{
+					Names: []*goast.Ident{goast.NewIdent(name + alternatePostfix)},
+					Type:  goast.NewIdent("int"),
+				},
+			},
+		}
+	}
 
 	p.ident++
 	fd.Body = &goast.BlockStmt{
 		Lbrace: 1,
 		List:   p.parseListStmt(),
 	}
+	if alternateReturn && len(returnType) == 0 {
+		// subroutine may end without RETURN
+		var last goast.Stmt
+		if n := len(fd.Body.List); n > 0 {
+			last = fd.Body.List[n-1]
+		}
+		if _, ok := last.(*goast.ReturnStmt); !ok {
+			fd.Body.List = append(fd.Body.List, &goast.ReturnStmt{})
+		}
+	}
+
+	// internal procedures
+	var internal []goast.Stmt
+	if p.ident < len(p.ns) && p.ns[p.ident].tok == ftContains {
+		internal = p.parseInternal()
+	}
+
+	// type of function result declared in body
+	// Example:
+	//  FUNCTION TWICE ( K )
+	//  INTEGER TWICE
+	if isFunction && len(returnType) == 0 {
+		addResult(p.resultType(result))
+	}
+	if result != "" && result != name {
+		p.initVars.del(result)
+	}
 
 	// delete external function type definition
 	p.removeExternalFunction()
@@ -1090,6 +1565,24 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 		}
 	}
 
+	// INTENT(IN) scalars of module and internal procedures passed by value
+	values := map[string]bool{}
+	for _, f := range fd.Type.Params.List {
+		if v, ok := p.initVars.get(f.Names[0].Name); ok && v.typ.value {
+			values[f.Names[0].Name] = true
+		}
+	}
+
+	// lower bounds of allocatable arguments are 1, until array is
+	// allocated in procedure
+	var lbounds []goast.Stmt
+	for _, f := range fd.Type.Params.List {
+		if v, ok := p.initVars.get(f.Names[0].Name); ok &&
+			v.typ.allocatable && p.lbounds[v.name] {
+			lbounds = append(lbounds, lowerBounds(v.name, v.typ))
+		}
+	}
+
 	// add correct type of subroutine arguments
 	arguments := p.argumentCorrection(fd)
 
@@ -1136,7 +1629,9 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 			}
 
 			// add pointer
-			id.Name = "*" + id.Name
+			if !values[fd.Type.Params.List[i].Names[0].Name] {
+				id.Name = "*" + id.Name
+			}
 		default:
 			panic(fmt.Errorf("Cannot parse type in fields: %T",
 				fd.Type.Params.List[i].Type))
@@ -1148,7 +1643,11 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 	goast.Walk(c, fd.Body)
 
 	// init vars
-	fd.Body.List = append(p.initializeVars(), fd.Body.List...)
+	vars := append(p.initializeVars(), lbounds...)
+	for _, s := range vars {
+		p.saveDecl(s)
+	}
+	fd.Body.List = append(vars, fd.Body.List...)
 
 	// remove unused labels
 	removedLabels := map[string]bool{}
@@ -1163,9 +1662,22 @@ func (p *parser) parseSubroutine() (decl goast.Decl) {
 	in := intrinsic{p: p}
 	goast.Walk(in, fd.Body)
 
+	goast.Walk(valueArgs{p: p}, fd.Body)
+
 	var cas callArgumentSimplification
 	goast.Walk(cas, fd.Body)
 
+	// names of entities of modules
+	if len(p.renames) > 0 {
+		goast.Walk(renamer(p.renames), &fd)
+	}
+
+	// internal procedures after initialization of variables
+	if len(internal) > 0 {
+		n := len(vars)
+		fd.Body.List = append(fd.Body.List[:n], append(internal, fd.Body.List[n:]...)...)
+	}
+
 	decl = &fd
 	return
 }
@@ -1230,6 +1742,18 @@ func (p *parser) parseListStmt() (stmts []goast.Stmt) {
 			// ELSE IF (...)...
 			break
 		}
+		if p.isCase() {
+			// next CASE of SELECT CASE
+			break
+		}
+		if p.isElseWhere() {
+			// next ELSEWHERE of WHERE
+			break
+		}
+		if p.ns[p.ident].tok == ftContains {
+			// procedures of module
+			break
+		}
 
 		stmt := p.parseStmt()
 		if len(stmt) == 0 {
@@ -1250,10 +1774,44 @@ func (p *parser) parseListStmt() (stmts []goast.Stmt) {
 //  DOUBLE PRECISION DX(*)
 //  LOGICAL CONJA,CONJB,NOTA,NOTB
 //  CHARACTER*32 SRNAME
+//
+// Declarations with attributes:
+//  REAL * 8 , ALLOCATABLE :: A ( : , : )
+//  INTEGER , DIMENSION ( : ) , ALLOCATABLE :: K
+//  REAL * 8 , PARAMETER :: PI = 3.14159D0
+//  INTEGER , PRIVATE :: COUNTER = 0
+//  REAL * 8 , OPTIONAL , INTENT ( IN ) :: TOL
 func (p *parser) parseInit() (stmts []goast.Stmt) {
 
 	// parse base type
 	var baseType []node
+	var dimension []node
+	var allocatable, parameter, optional, intentIn bool
+	var access string
+	if dc := p.findDoubleColon(); dc > 0 {
+		decl := append([]node{nodeLParen}, p.ns[p.ident:dc]...)
+		attrs, _ := separateArgsParen(append(decl, nodeRParen))
+		baseType = attrs[0]
+		for _, attr := range attrs[1:] {
+			switch strings.ToUpper(string(attr[0].b)) {
+			case "ALLOCATABLE":
+				allocatable = true
+			case "DIMENSION":
+				dimension = attr[1:]
+			case "PARAMETER":
+				parameter = true
+			case "PUBLIC", "PRIVATE":
+				access = strings.ToUpper(string(attr[0].b))
+			case "OPTIONAL":
+				optional = true
+			case "INTENT":
+				// Example:
+				//  INTENT ( IN )
+				intentIn = len(attr) == 4 && strings.ToUpper(string(attr[2].b)) == "IN"
+			}
+		}
+		p.ident = dc + 1
+	}
 	for ; p.ns[p.ident].tok != token.IDENT; p.ident++ {
 		baseType = append(baseType, p.ns[p.ident])
 	}
@@ -1266,6 +1824,7 @@ func (p *parser) parseInit() (stmts []goast.Stmt) {
 		// parse name
 		p.expect(token.IDENT)
 		name = string(p.ns[p.ident].b)
+		nameNode := p.ns[p.ident]
 
 		// parse addition type
 		additionType = []node{}
@@ -1294,8 +1853,54 @@ func (p *parser) parseInit() (stmts []goast.Stmt) {
 			additionType = append(additionType, p.ns[p.ident])
 		}
 
+		// initialization
+		// Example:
+		//  INTEGER :: N = 10
+		var value []node
+		for i := range additionType {
+			if additionType[i].tok == token.ASSIGN {
+				value = additionType[i+1:]
+				additionType = additionType[:i]
+				break
+			}
+		}
+
+		if len(additionType) == 0 || additionType[0].tok != token.LPAREN {
+			additionType = append(dimension, additionType...)
+		}
+
 		// parse type = base type + addition type
-		p.initVars.add(name, parseType(append(baseType, additionType...)))
+		typ := parseType(append(append([]node{}, baseType...), additionType...))
+		typ.allocatable = allocatable
+		typ.optional = optional
+		// callers of external procedures in other sources do not
+		// know about value arguments
+		typ.value = intentIn && p.contained && !optional && !allocatable &&
+			!typ.isArray() && typ.baseType != "byte"
+		p.initVars.add(name, typ)
+		if access != "" && p.module != nil {
+			p.module.access[strings.ToUpper(name)] = access == "PRIVATE"
+		}
+		if value != nil {
+			if parameter {
+				p.constants[name] = value
+			}
+			lhs := []node{nameNode}
+			var init []goast.Stmt
+			switch {
+			case parameter && p.moduleConst(name, value):
+				// Go constant
+			case p.isArrayAssign(lhs):
+				init = p.parseArrayAssign(lhs, value)
+			default:
+				init = []goast.Stmt{p.assignNodes(lhs, value)}
+			}
+			if !parameter && p.module == nil && len(init) > 0 {
+				// initialized variable of procedure has attribute SAVE
+				init = p.saveVar(name, init)
+			}
+			stmts = append(stmts, init...)
+		}
 		if p.ns[p.ident].tok != token.COMMA {
 			p.ident--
 		}
@@ -1304,9 +1909,20 @@ func (p *parser) parseInit() (stmts []goast.Stmt) {
 	return
 }
 
-func (p *parser) parseDoWhile() (sDo goast.ForStmt) {
-	p.expect(ftDo)
-	p.ident++
+// findDoubleColon return position of `::` in declaration or -1
+func (p *parser) findDoubleColon() int {
+	for i := p.ident; i < len(p.ns) && p.ns[i].tok != ftNewLine; i++ {
+		if p.ns[i].tok == ftDoubleColon {
+			return i
+		}
+	}
+	return -1
+}
+
+// Examples:
+//  DO WHILE ( I .LT. 10 )
+//  DO 10 WHILE ( I .LT. 10 )
+func (p *parser) parseDoWhile() (sDo *goast.ForStmt) {
 	p.expect(ftWhile)
 	p.ident++
 	start := p.ident
@@ -1315,7 +1931,8 @@ func (p *parser) parseDoWhile() (sDo goast.ForStmt) {
 			break
 		}
 	}
-	sDo.Cond = p.parseExpr(start, p.ident)
+	sDo = &goast.ForStmt{}
+	sDo.Cond = p.parseBinary(start, p.ident)
 
 	p.expect(ftNewLine)
 	p.ident++
@@ -1331,7 +1948,10 @@ func (p *parser) parseDoWhile() (sDo goast.ForStmt) {
 // Examples:
 // CALL XERBLA ( 'CGEMM ' , INFO )
 // CALL NORET
+//  CALL SOLVE ( A , N , *900 , *910 )
 func (p *parser) parseCall() goast.Stmt {
+	// labels of alternate returns
+	var labels []string
 	{
 		begin := p.ident
 		p.expect(ftCall)
@@ -1377,6 +1997,21 @@ func (p *parser) parseCall() goast.Stmt {
 			addNode()
 		}
 
+		// Alternate returns
+		{
+			var exp [][]node
+			for i := range args {
+				if len(args[i]) == 2 &&
+					(args[i][0].tok == token.MUL || args[i][0].tok == token.AND) &&
+					args[i][1].tok == token.INT {
+					labels = append(labels, string(args[i][1].b))
+					continue
+				}
+				exp = append(exp, args[i])
+			}
+			args = exp
+		}
+
 		// Explode loops
 		{
 			var exp [][]node
@@ -1421,18 +2056,51 @@ func (p *parser) parseCall() goast.Stmt {
 		}
 	}
 	p.expect(ftNewLine)
+	if len(labels) > 0 {
+		// Example:
+		//  switch SOLVE(A, N) {
+		//  case 1:
+		//  	goto Label900
+		//  case 2:
+		//  	goto Label910
+		//  }
+		var sw goast.SwitchStmt
+		sw.Tag = f
+		sw.Body = &goast.BlockStmt{}
+		for i := range labels {
+			p.foundLabels["Label"+labels[i]] = true
+			sw.Body.List = append(sw.Body.List, &goast.CaseClause{
+				List: []goast.Expr{goast.NewIdent(strconv.Itoa(i + 1))},
+				Body: []goast.Stmt{&goast.BranchStmt{
+					Tok:   token.GOTO,
+					Label: goast.NewIdent("Label" + labels[i]),
+				}},
+			})
+		}
+		return &sw
+	}
 	return &goast.ExprStmt{
 		X: f,
 	}
 }
 
-func (p *parser) parseDo() (sDo goast.ForStmt) {
+// doLoop is description of DO loop for statements EXIT and CYCLE
+type doLoop struct {
+	name        string // construct name
+	label       string // label of Go loop, if used
+	selectDepth int    // amount of SELECT CASE around loop
+}
+
+// Examples:
+//  DO 10 I = 1 , N
+//  DO 10 , I = N , 1 , - 1
+//  DO I = 1 , N , INCX
+//  DO WHILE ( I .LT. 10 )
+//  DO
+// For named loop `OUTER : DO I = 1 , N` name is `OUTER`.
+func (p *parser) parseDo(name string) (stmt goast.Stmt) {
 	p.expect(ftDo)
 	p.ident++
-	if p.ns[p.ident].tok == ftWhile {
-		p.ident--
-		return p.parseDoWhile()
-	}
 	// possible label
 	if p.ns[p.ident].tok == token.INT {
 		p.endLabelDo[string(p.ns[p.ident].b)]++
@@ -1443,100 +2111,267 @@ func (p *parser) parseDo() (sDo goast.ForStmt) {
 		p.ident++
 	}
 
+	p.loops = append(p.loops, doLoop{
+		name:        strings.ToUpper(name),
+		selectDepth: p.selectDepth,
+	})
+
+	var sDo *goast.ForStmt
+	switch p.ns[p.ident].tok {
+	case ftNewLine:
+		// infinite loop
+		p.ident++
+		sDo = &goast.ForStmt{
+			Body: &goast.BlockStmt{
+				Lbrace: 1,
+				List:   p.parseListStmt(),
+			},
+		}
+		stmt = sDo
+	case ftWhile:
+		sDo = p.parseDoWhile()
+		stmt = sDo
+	default:
+		stmt, sDo = p.parseDoIterative()
+	}
+
+	// add label of loop, if it used by EXIT or CYCLE
+	loop := p.loops[len(p.loops)-1]
+	p.loops = p.loops[:len(p.loops)-1]
+	if loop.label != "" {
+		labeled := &goast.LabeledStmt{
+			Label: goast.NewIdent(loop.label),
+			Colon: 1,
+			Stmt:  sDo,
+		}
+		if stmt == sDo {
+			return labeled
+		}
+		block := stmt.(*goast.BlockStmt)
+		block.List[len(block.List)-1] = labeled
+	}
+	return
+}
+
+// parseDoIterative parse DO loop with loop variable.
+// Iteration count is calculated once before loop, as in Fortran.
+//
+// Example:
+//  DO I = 1 , N , INCX
+// Go code:
+//  {
+//  	var start, end, step int = 1, (*N), (*INCX)
+//  	trip := (end - start + step) / step
+//  	for (*I) = start; trip > 0; (*I), trip = (*I)+step, trip-1 {
+//  	}
+//  }
+//
+// If step is constant and bound is literal or PARAMETER:
+//  DO I = 1 , 10
+// Go code:
+//  for (*I) = 1; (*I) <= 10; (*I)++ {
+//  }
+func (p *parser) parseDoIterative() (stmt goast.Stmt, sDo *goast.ForStmt) {
 	p.expect(token.IDENT)
 	name := p.ns[p.ident]
 
 	p.ident++
 	p.expect(token.ASSIGN)
-
 	p.ident++
-	// Init is expression
-	start := p.ident
+
+	// parse expressions: init, bound and step
+	var exprs [][]node
+	exprs = append(exprs, nil)
 	counter := 0
-	for ; p.ident < len(p.ns); p.ident++ {
-		if p.ns[p.ident].tok == token.LPAREN {
+	for ; p.ident < len(p.ns) && p.ns[p.ident].tok != ftNewLine; p.ident++ {
+		switch p.ns[p.ident].tok {
+		case token.LPAREN:
 			counter++
-			continue
-		}
-		if p.ns[p.ident].tok == token.RPAREN {
+		case token.RPAREN:
 			counter--
-			continue
-		}
-		if p.ns[p.ident].tok == token.COMMA && counter == 0 {
-			break
+		case token.COMMA:
+			if counter == 0 {
+				exprs = append(exprs, nil)
+				continue
+			}
 		}
+		exprs[len(exprs)-1] = append(exprs[len(exprs)-1], p.ns[p.ident])
 	}
-	sDo.Init = &goast.AssignStmt{
-		Lhs: []goast.Expr{
-			p.parseExprNodes([]node{name}),
-		},
-		Tok: token.ASSIGN, // =
-		Rhs: []goast.Expr{
-			p.parseExpr(start, p.ident),
-		},
+	if len(exprs) < 2 || 3 < len(exprs) {
+		panic(fmt.Errorf("Not valid DO loop: %s", p.getLine()))
 	}
+	p.expect(ftNewLine)
 
-	p.expect(token.COMMA)
-
-	// Cond is expression
-	p.ident++
-	start = p.ident
-	p.ns = append(p.ns[:start], append([]node{
-		name,
-		{tok: token.LEQ, b: []byte{'<', '='}},
-	}, p.ns[start:]...)...)
-	counter = 0
-	for ; p.ident < len(p.ns); p.ident++ {
-		if p.ns[p.ident].tok == token.LPAREN {
-			counter++
-			continue
-		}
-		if p.ns[p.ident].tok == token.RPAREN {
-			counter--
-			continue
-		}
-		if (p.ns[p.ident].tok == token.COMMA || p.ns[p.ident].tok == ftNewLine) &&
-			counter == 0 {
-			break
+	body := p.parseListStmt()
+
+	// type of loop variable
+	var typ string
+	if v, ok := p.initVars.get(string(name.b)); ok && !v.typ.isArray() {
+		typ = v.typ.getBaseType()
+	}
+
+	// step is constant
+	var step []node
+	isConstStep := true
+	isNegativeStep := false
+	if len(exprs) == 3 {
+		step = exprs[2]
+		switch {
+		case len(step) == 1 && step[0].tok == token.INT:
+		case len(step) == 2 && step[1].tok == token.INT &&
+			(step[0].tok == token.ADD || step[0].tok == token.SUB):
+			isNegativeStep = step[0].tok == token.SUB
+		default:
+			isConstStep = false
 		}
 	}
-	sDo.Cond = p.parseBinary(start, p.ident)
 
-	if p.ns[p.ident].tok == ftNewLine {
-		sDo.Post = &goast.IncDecStmt{
-			X:   p.parseExprNodes([]node{name}),
-			Tok: token.INC,
+	if isConstStep && strings.HasPrefix(typ, "int") && p.isConstant(exprs[1]) {
+		sDo = &goast.ForStmt{}
+		sDo.Init = &goast.AssignStmt{
+			Lhs: []goast.Expr{p.parseExprNodes([]node{name})},
+			Tok: token.ASSIGN, // =
+			Rhs: []goast.Expr{p.parseValue(exprs[0])},
 		}
-	} else {
-		p.expect(token.COMMA)
-		p.ident++
-
-		// Post is expression
-		start = p.ident
-		for ; p.ident < len(p.ns); p.ident++ {
-			if p.ns[p.ident].tok == ftNewLine {
-				break
+		op := node{tok: token.LEQ, b: []byte("<=")}
+		if isNegativeStep {
+			op = node{tok: token.GEQ, b: []byte(">=")}
+		}
+		sDo.Cond = p.parseBinaryNodes(append([]node{name, op}, exprs[1]...))
+		if len(step) == 0 {
+			sDo.Post = &goast.IncDecStmt{
+				X:   p.parseExprNodes([]node{name}),
+				Tok: token.INC,
+			}
+		} else {
+			sDo.Post = &goast.AssignStmt{
+				Lhs: []goast.Expr{p.parseExprNodes([]node{name})},
+				Tok: token.ADD_ASSIGN, // +=
+				Rhs: []goast.Expr{p.parseExprNodes(step)},
 			}
 		}
-		sDo.Post = &goast.AssignStmt{
-			Lhs: []goast.Expr{p.parseExprNodes([]node{name})},
-			Tok: token.ADD_ASSIGN, // +=
-			Rhs: []goast.Expr{p.parseExpr(start, p.ident)},
+		sDo.Body = &goast.BlockStmt{
+			Lbrace: 1,
+			List:   body,
 		}
+		return sDo, sDo
 	}
 
-	p.expect(ftNewLine)
-
-	sDo.Body = &goast.BlockStmt{
+	// loop with trip count
+	var values []goast.Expr
+	for i := range exprs {
+		values = append(values, p.parseValue(exprs[i]))
+	}
+	if len(values) == 2 {
+		values = append(values, goast.NewIdent("1"))
+	}
+	spec := &goast.ValueSpec{
+		Names: []*goast.Ident{
+			goast.NewIdent("start"),
+			goast.NewIdent("end"),
+			goast.NewIdent("step"),
+		},
+		Values: values,
+	}
+	if typ != "" {
+		spec.Type = goast.NewIdent(typ)
+	}
+	tripExpr, err := goparser.ParseExpr("(end - start + step) / step")
+	if err != nil {
+		panic(err)
+	}
+	if !strings.HasPrefix(typ, "int") {
+		// Go conversion of REAL trip count
+		tripExpr = &goast.CallExpr{
+			Fun:  goast.NewIdent("int"),
+			Args: []goast.Expr{tripExpr},
+		}
+	}
+	sDo = &goast.ForStmt{
+		Init: &goast.AssignStmt{
+			Lhs: []goast.Expr{p.parseExprNodes([]node{name})},
+			Tok: token.ASSIGN, // =
+			Rhs: []goast.Expr{goast.NewIdent("start")},
+		},
+		Cond: &goast.BinaryExpr{
+			X:  goast.NewIdent("trip"),
+			Op: token.GTR,
+			Y:  goast.NewIdent("0"),
+		},
+		Post: &goast.AssignStmt{
+			Lhs: []goast.Expr{
+				p.parseExprNodes([]node{name}),
+				goast.NewIdent("trip"),
+			},
+			Tok: token.ASSIGN, // =
+			Rhs: []goast.Expr{
+				&goast.BinaryExpr{
+					X:  p.parseExprNodes([]node{name}),
+					Op: token.ADD,
+					Y:  goast.NewIdent("step"),
+				},
+				&goast.BinaryExpr{
+					X:  goast.NewIdent("trip"),
+					Op: token.SUB,
+					Y:  goast.NewIdent("1"),
+				},
+			},
+		},
+		Body: &goast.BlockStmt{
+			Lbrace: 1,
+			List:   body,
+		},
+	}
+	stmt = &goast.BlockStmt{
 		Lbrace: 1,
-		List:   p.parseListStmt(),
+		List: []goast.Stmt{
+			&goast.DeclStmt{Decl: &goast.GenDecl{
+				Tok:   token.VAR,
+				Specs: []goast.Spec{spec},
+			}},
+			&goast.AssignStmt{
+				Lhs: []goast.Expr{goast.NewIdent("trip")},
+				Tok: token.DEFINE, // :=
+				Rhs: []goast.Expr{tripExpr},
+			},
+			sDo,
+		},
 	}
-
 	return
 }
 
+// isConstant return true if expression have only literals, PARAMETER
+// constants and intrinsic functions, so value is not changed inside loop.
+func (p *parser) isConstant(nodes []node) bool {
+	for i := range nodes {
+		if nodes[i].tok != token.IDENT {
+			continue
+		}
+		name := string(nodes[i].b)
+		if i+1 < len(nodes) && nodes[i+1].tok == token.LPAREN {
+			if _, ok := intrinsicFunction[strings.ToUpper(name)]; ok &&
+				!p.isArrayVariable(strings.ToUpper(name)) {
+				continue
+			}
+			return false
+		}
+		v, ok := p.initVars.get(name)
+		if !ok {
+			return false
+		}
+		if _, ok := p.constants[v.name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *parser) parseBinary(start, finish int) (expr goast.Expr) {
-	expr = p.parseExpr(start, finish)
+	return p.parseBinaryNodes(p.ns[start:finish])
+}
+
+func (p *parser) parseBinaryNodes(nodes []node) (expr goast.Expr) {
+	expr = p.parseExprNodes(nodes)
 	if b, ok := expr.(*goast.BinaryExpr); ok {
 		if _, ok := b.X.(*goast.CallExpr); ok {
 			b.X = &goast.ParenExpr{X: &goast.StarExpr{X: b.X}}
@@ -1548,6 +2383,15 @@ func (p *parser) parseBinary(start, finish int) (expr goast.Expr) {
 	return
 }
 
+// parseValue parse expression with value of function result
+func (p *parser) parseValue(nodes []node) (expr goast.Expr) {
+	expr = p.parseExprNodes(nodes)
+	if f, ok := expr.(*goast.CallExpr); ok && !isIgnoreCall(f) {
+		expr = &goast.ParenExpr{X: &goast.StarExpr{X: expr}}
+	}
+	return
+}
+
 func (p *parser) parseIf() (sIf goast.IfStmt) {
 	p.ident++
 	p.expect(token.LPAREN)
@@ -1676,8 +2520,12 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		p.addError(p.getLine())
 		p.gotoEndLine()
 
-	case ftRewind:
-		s := p.parseRewind()
+	case ftRewind, ftBackspace, ftEndfile:
+		s := p.parseFilePositioning()
+		stmts = append(stmts, s...)
+
+	case ftInquire:
+		s := p.parseInquire()
 		stmts = append(stmts, s...)
 
 	case ftDimension:
@@ -1720,9 +2568,19 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		stmts = append(stmts, s...)
 
 	case token.RETURN:
-		stmts = append(stmts, &goast.ReturnStmt{})
+		// Examples:
+		//  RETURN
+		//  RETURN 1
+		p.expect(token.RETURN)
+		p.ident++
+		start := p.ident
 		p.gotoEndLine()
 		p.expect(ftNewLine)
+		var ret goast.ReturnStmt
+		if start < p.ident {
+			ret.Results = []goast.Expr{p.parseExpr(start, p.ident)}
+		}
+		stmts = append(stmts, &ret)
 
 	case ftParameter:
 		//  PARAMETER ( ONE = ( 1.0E+0 , 0.0E+0 )  , ZERO = 0.0E+0 )
@@ -1758,6 +2616,21 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 	case ftExternal:
 		p.parseExternal()
 
+	case ftUse:
+		// Example:
+		//  USE SHAPES , ONLY : PI
+		p.parseUse()
+
+	case ftInterface:
+		// Example:
+		//  INTERFACE NORM
+		p.parseInterface()
+
+	case ftNamelist:
+		// Example:
+		//  NAMELIST / PARAMS / DT , NSTEPS
+		p.parseNamelist()
+
 	case ftNewLine:
 		// ignore
 		p.ident++
@@ -1767,8 +2640,13 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		stmts = append(stmts, &sIf)
 
 	case ftDo:
-		sDo := p.parseDo()
-		stmts = append(stmts, &sDo)
+		stmts = append(stmts, p.parseDo(""))
+
+	case ftExit, ftCycle:
+		// Examples:
+		//  EXIT
+		//  CYCLE OUTER
+		stmts = append(stmts, p.parseExitCycle())
 
 	case ftCall:
 		// Example:
@@ -1793,25 +2671,17 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		sWrite := p.parseWrite()
 		stmts = append(stmts, sWrite...)
 
-	case ftStop:
-		p.expect(ftStop)
-		p.ident++
-		var msg []byte
-		for ; p.ident < len(p.ns) && p.ns[p.ident].tok != ftNewLine; p.ident++ {
-			msg = append(msg, p.ns[p.ident].b...)
-		}
-		stmts = append(stmts, &goast.ExprStmt{
-			X: &goast.CallExpr{
-				Fun:    goast.NewIdent("panic"),
-				Lparen: 1,
-				Args: []goast.Expr{
-					&goast.BasicLit{
-						Kind:  token.STRING,
-						Value: "\"" + string(msg) + "\"",
-					},
-				},
-			},
-		})
+	case ftPrint:
+		s := p.parsePrint()
+		stmts = append(stmts, s...)
+
+	case ftStop, ftPause:
+		// Examples:
+		//  STOP
+		//  STOP 3
+		//  STOP 'Done'
+		//  PAUSE
+		stmts = append(stmts, p.parseStop("STOP"))
 
 	case token.GOTO:
 		// Examples:
@@ -1849,12 +2719,25 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		labelName := string(p.ns[p.ident].b)
 		if v, ok := p.endLabelDo[labelName]; ok && v > 0 {
 			stmts = append(stmts, p.addLabel(p.ns[p.ident].b))
-			// if after END DO, then remove
-			for i := p.ident; p.ns[i].tok != ftNewLine; i++ {
-				p.ns[i].tok, p.ns[i].b = ftNewLine, []byte("\n")
+			end := p.ident
+			for ; end < len(p.ns) && p.ns[end].tok != ftNewLine; end++ {
+			}
+			switch p.ns[p.ident+1].tok {
+			case token.CONTINUE, ftEnd:
+				// Examples:
+				//  10 CONTINUE
+				//  10 END DO
+				for i := p.ident; i < end; i++ {
+					p.ns[i].tok, p.ns[i].b = ftNewLine, []byte("\n")
+				}
+			default:
+				// terminal statement of loop
+				// Example:
+				//  10 Y(I) = 0
+				p.ns[p.ident].tok, p.ns[p.ident].b = ftNewLine, []byte("\n")
 			}
 
-			// add END DO before that label
+			// add END DO after that statement
 			var add []node
 			for j := 0; j < v; j++ {
 				add = append(add, []node{
@@ -1864,16 +2747,9 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 				}...)
 			}
 			var comb []node
-			comb = append(comb, p.ns[:p.ident-1]...)
-			comb = append(comb, []node{
-				{tok: ftNewLine, b: []byte("\n")},
-				{tok: ftNewLine, b: []byte("\n")},
-			}...)
+			comb = append(comb, p.ns[:end]...)
 			comb = append(comb, add...)
-			comb = append(comb, []node{
-				{tok: ftNewLine, b: []byte("\n")},
-			}...)
-			comb = append(comb, p.ns[p.ident-1:]...)
+			comb = append(comb, p.ns[end:]...)
 			p.ns = comb
 			// remove do labels from map
 			p.endLabelDo[labelName] = 0
@@ -1893,6 +2769,68 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 		return
 
 	default:
+		// Construct name
+		// Example:
+		//  OUTER : DO I = 1 , N
+		if p.ns[p.ident].tok == token.IDENT && p.ident+2 < len(p.ns) &&
+			p.ns[p.ident+1].tok == token.COLON {
+			name := string(p.ns[p.ident].b)
+			p.ident += 2
+			if p.ns[p.ident].tok == ftDo {
+				stmts = append(stmts, p.parseDo(name))
+				return
+			}
+			return p.parseStmt()
+		}
+
+		if p.isSelect() {
+			stmts = append(stmts, p.parseSelect()...)
+			return
+		}
+
+		if p.isStatement("ALLOCATE") {
+			stmts = append(stmts, p.parseAllocate()...)
+			return
+		}
+
+		if p.isStatement("DEALLOCATE") {
+			stmts = append(stmts, p.parseDeallocate()...)
+			return
+		}
+
+		if p.ns[p.ident].tok == token.IDENT &&
+			strings.ToUpper(string(p.ns[p.ident].b)) == "ALLOCATABLE" &&
+			p.ident+1 < len(p.ns) && p.ns[p.ident+1].tok != token.ASSIGN &&
+			p.ns[p.ident+1].tok != token.LPAREN {
+			p.parseAllocatable()
+			return
+		}
+
+		if p.isAccess() {
+			p.parseAccess()
+			return
+		}
+
+		if p.isStatement("WHERE") {
+			stmts = append(stmts, p.parseWhere()...)
+			return
+		}
+
+		if p.isStatement("FORALL") {
+			stmts = append(stmts, p.parseForall()...)
+			return
+		}
+
+		// Example:
+		//  ERROR STOP 'Cannot solve'
+		if p.ns[p.ident].tok == token.IDENT && p.ident+1 < len(p.ns) &&
+			strings.ToUpper(string(p.ns[p.ident].b)) == "ERROR" &&
+			p.ns[p.ident+1].tok == ftStop {
+			p.ident++
+			stmts = append(stmts, p.parseStop("ERROR_STOP"))
+			return
+		}
+
 		start := p.ident
 		for ; p.ident < len(p.ns); p.ident++ {
 			if p.ns[p.ident].tok == ftNewLine {
@@ -1954,18 +2892,17 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 				}
 			}
 
-			// add assign
-			assign := goast.AssignStmt{
-				Lhs: []goast.Expr{p.parseExpr(start, pos)},
-				Tok: token.ASSIGN, // =
-				Rhs: []goast.Expr{p.parseExpr(pos+1, p.ident)},
-			}
-			if f, ok := assign.Rhs[0].(*goast.CallExpr); ok {
-				if !isIgnoreCall(f) {
-					assign.Rhs[0] = &goast.ParenExpr{X: &goast.StarExpr{X: assign.Rhs[0]}}
-				}
+			if p.isArrayAssign(p.ns[start:pos]) {
+				// Examples:
+				//  A = B + C
+				//  A ( 2 : N : 2 ) = 0
+				stmts = append(stmts, p.parseArrayAssign(p.ns[start:pos], p.ns[pos+1:p.ident])...)
+				p.ident++
+				return
 			}
-			stmts = append(stmts, &assign)
+
+			// add assign
+			stmts = append(stmts, p.assignNodes(p.ns[start:pos], p.ns[pos+1:p.ident]))
 		} else {
 			nodes := p.parseExpr(start, p.ident)
 			stmts = append(stmts, &goast.ExprStmt{
@@ -1979,6 +2916,86 @@ func (p *parser) parseStmt() (stmts []goast.Stmt) {
 	return
 }
 
+// parseStop parse statements STOP, ERROR STOP, PAUSE with
+// optional integer code or character message.
+// Example:
+//  STOP 3
+// Go code:
+//  intrinsic.STOP(3)
+func (p *parser) parseStop(name string) (stmt goast.Stmt) {
+	if p.ns[p.ident].tok == ftPause {
+		name = "PAUSE"
+	}
+	p.ident++
+	start := p.ident
+	p.gotoEndLine()
+
+	call := &goast.CallExpr{
+		Fun: &goast.SelectorExpr{
+			X:   goast.NewIdent("intrinsic"),
+			Sel: goast.NewIdent(name),
+		},
+		Lparen: 1,
+	}
+	if start < p.ident {
+		// character with one character is not INTEGER*4 code
+		call.Args = append(call.Args,
+			characterByte(p.parseValue(p.ns[start:p.ident])))
+	}
+	p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+	return &goast.ExprStmt{X: call}
+}
+
+// Examples:
+//  EXIT
+//  EXIT OUTER
+//  CYCLE
+//  CYCLE OUTER
+func (p *parser) parseExitCycle() (stmt goast.Stmt) {
+	isExit := p.ns[p.ident].tok == ftExit
+	p.ident++
+	var name string
+	if p.ns[p.ident].tok == token.IDENT {
+		name = strings.ToUpper(string(p.ns[p.ident].b))
+		p.ident++
+	}
+	p.expect(ftNewLine)
+
+	index := len(p.loops) - 1
+	if name != "" {
+		for ; index >= 0; index-- {
+			if p.loops[index].name == name {
+				break
+			}
+		}
+	}
+	if index < 0 {
+		panic(fmt.Errorf("Cannot find DO loop for EXIT or CYCLE: %s", name))
+	}
+
+	branch := &goast.BranchStmt{Tok: token.CONTINUE}
+	if isExit {
+		branch.Tok = token.BREAK
+	}
+
+	// label is not need for innermost loop,
+	// but statement break inside switch is break of switch
+	if index == len(p.loops)-1 &&
+		!(isExit && p.selectDepth > p.loops[index].selectDepth) {
+		return branch
+	}
+	if p.loops[index].label == "" {
+		if p.loops[index].name != "" {
+			p.loops[index].label = p.loops[index].name
+		} else {
+			p.loopLabels++
+			p.loops[index].label = fmt.Sprintf("Loop%d", p.loopLabels)
+		}
+	}
+	branch.Label = goast.NewIdent(p.loops[index].label)
+	return branch
+}
+
 func (p *parser) addLabel(label []byte) (stmt goast.Stmt) {
 	labelName := "Label" + string(label)
 	p.allLabels[labelName] = true
@@ -1989,7 +3006,10 @@ func (p *parser) addLabel(label []byte) (stmt goast.Stmt) {
 	}
 }
 
-func (p *parser) parseParamDecl() (fields []*goast.Field) {
+// Example:
+//  ( A , N , * )
+// Dummy argument `*` is alternate return and not added in fields.
+func (p *parser) parseParamDecl() (fields []*goast.Field, alternateReturn bool) {
 	if p.ns[p.ident].tok != token.LPAREN {
 		// Function or SUBROUTINE without arguments
 		// Example:
@@ -2012,6 +3032,8 @@ func (p *parser) parseParamDecl() (fields []*goast.Field) {
 				Type:  goast.NewIdent("int"),
 			}
 			fields = append(fields, field)
+		case token.MUL:
+			alternateReturn = true
 		case token.RPAREN:
 			p.ident--
 			exit = true
@@ -2270,16 +3292,25 @@ namesExplode:
 
 		var postfix []node
 		postfix = append(postfix, node{tok: token.RPAREN, b: []byte(")")})
+		col := lenArray - 1 - exs
 		if len(name) > 1 {
 			postfix = append([]node{{tok: token.COMMA, b: []byte(",")}}, name[2:len(name)]...)
+			if v.typ.baseType == "byte" {
+				// length of CHARACTER is last dimension
+				// L(1) ->     L(1,|  |)      -> L(1,1), L(1,2)
+				prefix = append(append([]node{}, name[:len(name)-1]...),
+					node{tok: token.COMMA, b: []byte(",")})
+				postfix = []node{{tok: token.RPAREN, b: []byte(")")}}
+				col = lenArray - 1
+			}
 		}
 
 		var inject [][]node
 		switch lenArray - exs {
 		case 1:
 			var (
-				s1, _    = v.typ.getMinLimit(lenArray - 1 - exs)
-				size1, _ = p.getSize(v.name, lenArray-1-exs)
+				s1, _    = v.typ.getMinLimit(col)
+				size1, _ = p.getSize(v.name, col)
 			)
 			for i := 1; i <= size1; i++ {
 				var sl []node
@@ -2553,6 +3584,7 @@ func (p *parser) parseParameter() (stmts []goast.Stmt) {
 	p.expect(token.LPAREN)
 	// parse values
 	var names [][]node
+	var starts []int // positions of values
 	counter := 1
 	p.ident++
 	for ; p.ident < len(p.ns); p.ident++ {
@@ -2567,20 +3599,28 @@ func (p *parser) parseParameter() (stmts []goast.Stmt) {
 		}
 		if p.ns[p.ident].tok == token.COMMA && counter == 1 {
 			names = append(names, []node{})
+			starts = append(starts, p.ident+1)
 			continue
 		}
 		if len(names) == 0 {
 			names = append(names, []node{})
+			starts = append(starts, p.ident)
 		}
 		names[len(names)-1] = append(names[len(names)-1], p.ns[p.ident])
 	}
 
 	// split to name and value
-	for _, val := range names {
+	for k, val := range names {
 		for i := 0; i < len(val); i++ {
 			if val[i].tok == token.ASSIGN {
 				// add parameters in parser
 				p.constants[nodesToString(val[:i])] = val[i+1:]
+				if p.moduleConst(nodesToString(val[:i]), val[i+1:]) {
+					// Go constant of module is not assigned
+					for j := starts[k]; j < starts[k]+len(val); j++ {
+						p.ns[j].tok, p.ns[j].b = ftNewLine, []byte("\n")
+					}
+				}
 			}
 		}
 	}
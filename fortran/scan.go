@@ -20,6 +20,8 @@ type node struct {
 	tok token.Token
 	b   []byte
 	pos position
+
+	double bool // FLOAT with exponent D or Q
 }
 
 func (e node) String() string {
@@ -573,6 +575,39 @@ A:
 	if changed {
 		goto A
 	}
+
+	// defined operators
+	// Example:
+	//  A.CROSS.B
+	for e := s.nodes.Front(); e != nil; e = e.Next() {
+		if e.Value.(*node).tok != ftUndefine {
+			continue
+		}
+		if start, end, ok := definedOperator(e.Value.(*node).b); ok {
+			s.extract(start, end, e, ftDefinedOp)
+			goto A
+		}
+	}
+}
+
+// definedOperator return position of defined operator `.NAME.`.
+// Points of numbers are ignored.
+// Example:
+//  1.E0
+func definedOperator(b []byte) (start, end int, ok bool) {
+	for start = 0; start < len(b); start++ {
+		if b[start] != '.' || (start > 0 && isDigit(b[start-1])) {
+			continue
+		}
+		end = start + 1
+		for end < len(b) && isLetter(b[end]) {
+			end++
+		}
+		if end > start+1 && end < len(b) && b[end] == '.' {
+			return start, end + 1, true
+		}
+	}
+	return 0, 0, false
 }
 
 // postprocessor
@@ -602,6 +637,13 @@ func (s *scanner) postprocessor() {
 		if n := e.Next(); n != nil && n.Value.(*node).tok == token.ASSIGN {
 			continue
 		}
+		// for: END FILE 10
+		if n := e.Next(); n != nil && n.Value.(*node).tok == token.IDENT &&
+			strings.ToUpper(string(n.Value.(*node).b)) == "FILE" {
+			e.Value.(*node).tok, e.Value.(*node).b = ftEndfile, []byte("ENDFILE")
+			s.nodes.Remove(n)
+			continue
+		}
 		for n := e.Next(); n != nil; n = e.Next() {
 			if n.Value.(*node).tok != ftNewLine {
 				s.nodes.Remove(n)
@@ -689,6 +731,7 @@ multi:
 		if e.Value.(*node).tok != token.FLOAT {
 			continue
 		}
+		e.Value.(*node).double = bytes.ContainsAny(e.Value.(*node).b, "dDqQ")
 		e.Value.(*node).b = []byte(strings.ToLower(string(e.Value.(*node).b)))
 		e.Value.(*node).b = []byte(strings.Replace(string(e.Value.(*node).b), "d", "e", -1))
 		e.Value.(*node).b = []byte(strings.Replace(string(e.Value.(*node).b), "q", "e", -1))
@@ -978,7 +1021,7 @@ func (s *scanner) scanTokens() {
 		{tok: ftReal, pattern: []string{"REAL"}},
 		{tok: ftData, pattern: []string{"DATA"}},
 		{tok: ftExternal, pattern: []string{"EXTERNAL"}},
-		{tok: ftEnd, pattern: []string{"END", "ENDDO", "ENDIF"}},
+		{tok: ftEnd, pattern: []string{"END", "ENDDO", "ENDIF", "ENDWHERE", "ENDFORALL"}},
 		{tok: ftDo, pattern: []string{"DO"}},
 		{tok: ftDouble, pattern: []string{"DOUBLE"}},
 		{tok: ftDimension, pattern: []string{"DIMENSION"}},
@@ -1009,6 +1052,18 @@ func (s *scanner) scanTokens() {
 		{tok: ftCommon, pattern: []string{"COMMON"}},
 		{tok: ftRewind, pattern: []string{"REWIND"}},
 		{tok: ftInclude, pattern: []string{"INCLUDE"}},
+		{tok: ftExit, pattern: []string{"EXIT"}},
+		{tok: ftCycle, pattern: []string{"CYCLE"}},
+		{tok: ftPause, pattern: []string{"PAUSE"}},
+		{tok: ftModule, pattern: []string{"MODULE"}},
+		{tok: ftUse, pattern: []string{"USE"}},
+		{tok: ftContains, pattern: []string{"CONTAINS"}},
+		{tok: ftInterface, pattern: []string{"INTERFACE"}},
+		{tok: ftNamelist, pattern: []string{"NAMELIST"}},
+		{tok: ftInquire, pattern: []string{"INQUIRE"}},
+		{tok: ftBackspace, pattern: []string{"BACKSPACE"}},
+		{tok: ftEndfile, pattern: []string{"ENDFILE"}},
+		{tok: ftPrint, pattern: []string{"PRINT"}},
 	}
 	for _, ent := range entities {
 		for _, pat := range ent.pattern {
@@ -0,0 +1,220 @@
+package fortran
+
+import (
+	"fmt"
+	goast "go/ast"
+	"go/token"
+	"strings"
+)
+
+// isSelect return true for statement:
+//  SELECT CASE ( expr )
+func (p *parser) isSelect() bool {
+	return p.ident+2 < len(p.ns) &&
+		p.ns[p.ident].tok == token.IDENT &&
+		strings.ToUpper(string(p.ns[p.ident].b)) == "SELECT" &&
+		p.ns[p.ident+1].tok == token.IDENT &&
+		strings.ToUpper(string(p.ns[p.ident+1].b)) == "CASE" &&
+		p.ns[p.ident+2].tok == token.LPAREN
+}
+
+// isCase return true for statements:
+//  CASE ( 1 , 3 : 5 )
+//  CASE DEFAULT
+func (p *parser) isCase() bool {
+	if p.ident+1 >= len(p.ns) ||
+		p.ns[p.ident].tok != token.IDENT ||
+		strings.ToUpper(string(p.ns[p.ident].b)) != "CASE" {
+		return false
+	}
+	next := p.ns[p.ident+1]
+	if next.tok == token.IDENT && strings.ToUpper(string(next.b)) == "DEFAULT" {
+		return true
+	}
+	if next.tok != token.LPAREN {
+		return false
+	}
+	// ignore assignment to array with name CASE
+	_, end := separateArgsParen(p.ns[p.ident+1:])
+	pos := p.ident + 1 + end
+	return pos >= len(p.ns) || p.ns[pos].tok != token.ASSIGN
+}
+
+// isCharacter return true for character expression
+func (p *parser) isCharacter(nodes []node) bool {
+	if len(nodes) == 0 {
+		return false
+	}
+	switch nodes[0].tok {
+	case token.STRING:
+		return true
+	case token.IDENT:
+		if v, ok := p.initVars.get(string(nodes[0].b)); ok {
+			return v.typ.baseType == "byte"
+		}
+	}
+	return false
+}
+
+// Example:
+//  SELECT CASE ( I )
+//  CASE ( 1 , 3 : 5 )
+//  ...
+//  CASE ( : 0 , 7 : )
+//  ...
+//  CASE DEFAULT
+//  ...
+//  END SELECT
+//
+// Go code with ranges:
+//  switch selector := (*I); {
+//  case selector == 1, selector >= 3 && selector <= 5:
+//  ...
+//  }
+//
+// For character selector used blank-padded comparison.
+func (p *parser) parseSelect() (stmts []goast.Stmt) {
+	if !p.isSelect() {
+		panic(fmt.Errorf("Not valid SELECT CASE statement"))
+	}
+	p.ident += 2
+	p.expect(token.LPAREN)
+	args, end := separateArgsParen(p.ns[p.ident:])
+	if len(args) != 1 {
+		panic(fmt.Errorf("Not valid selector of SELECT CASE"))
+	}
+	selector := args[0]
+	isChar := p.isCharacter(selector)
+	p.ident += end
+	p.gotoEndLine()
+	p.expect(ftNewLine)
+	p.ident++
+
+	p.selectDepth++
+	defer func() {
+		p.selectDepth--
+	}()
+
+	// comments before first CASE
+	stmts = append(stmts, p.parseListStmt()...)
+
+	var sw goast.SwitchStmt
+	sw.Body = &goast.BlockStmt{}
+	var haveRange bool
+	for p.ident < len(p.ns) && p.isCase() {
+		var cc goast.CaseClause
+		p.ident++
+		if p.ns[p.ident].tok == token.LPAREN {
+			values, end := separateArgsParen(p.ns[p.ident:])
+			for _, v := range values {
+				var low, high []node
+				isRange := false
+				counter := 0
+				for i := range v {
+					switch v[i].tok {
+					case token.LPAREN:
+						counter++
+					case token.RPAREN:
+						counter--
+					case token.COLON:
+						if counter == 0 && !isRange {
+							isRange = true
+							low, high = v[:i], v[i+1:]
+						}
+					}
+				}
+				if isRange || isChar {
+					haveRange = true
+				}
+				if !isRange {
+					low, high = v, v
+				}
+				cc.List = append(cc.List, p.caseCondition(low, high, isChar))
+			}
+			p.ident += end
+		}
+		// ignore construct name or DEFAULT
+		p.gotoEndLine()
+		p.expect(ftNewLine)
+		p.ident++
+
+		cc.Body = p.parseListStmt()
+		sw.Body.List = append(sw.Body.List, &cc)
+
+		if p.ident >= len(p.ns) || !p.isCase() {
+			// END SELECT
+			break
+		}
+	}
+
+	if haveRange {
+		// switch selector := expr; {
+		// case selector >= 3 && selector <= 5:
+		sw.Init = &goast.AssignStmt{
+			Lhs: []goast.Expr{goast.NewIdent("selector")},
+			Tok: token.DEFINE,
+			Rhs: []goast.Expr{p.parseExprNodes(selector)},
+		}
+	} else {
+		// switch expr {
+		// case 1, 3:
+		sw.Tag = p.parseExprNodes(selector)
+		for _, c := range sw.Body.List {
+			cc := c.(*goast.CaseClause)
+			for i := range cc.List {
+				cc.List[i] = cc.List[i].(*goast.BinaryExpr).Y
+			}
+		}
+	}
+
+	stmts = append(stmts, &sw)
+	return
+}
+
+// caseCondition return condition for one value or range of CASE.
+// For value low and high is same.
+func (p *parser) caseCondition(low, high []node, isChar bool) goast.Expr {
+	selector := goast.NewIdent("selector")
+	var conds []goast.Expr
+	if len(low) > 0 {
+		if isChar {
+			p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+			conds = append(conds, &goast.CallExpr{
+				Fun:  &goast.SelectorExpr{X: goast.NewIdent("intrinsic"), Sel: goast.NewIdent("LGE")},
+				Args: []goast.Expr{selector, p.parseExprNodes(low)},
+			})
+		} else {
+			op := token.GEQ
+			if nodesToString(low) == nodesToString(high) {
+				op = token.EQL
+			}
+			conds = append(conds, &goast.BinaryExpr{
+				X:  selector,
+				Op: op,
+				Y:  p.parseExprNodes(low),
+			})
+		}
+	}
+	if len(high) > 0 && (isChar || nodesToString(low) != nodesToString(high)) {
+		if isChar {
+			p.addImport("github.com/Konstantin8105/f4go/intrinsic")
+			conds = append(conds, &goast.CallExpr{
+				Fun:  &goast.SelectorExpr{X: goast.NewIdent("intrinsic"), Sel: goast.NewIdent("LLE")},
+				Args: []goast.Expr{selector, p.parseExprNodes(high)},
+			})
+		} else {
+			conds = append(conds, &goast.BinaryExpr{
+				X:  selector,
+				Op: token.LEQ,
+				Y:  p.parseExprNodes(high),
+			})
+		}
+	}
+	if len(conds) == 0 {
+		panic(fmt.Errorf("Not valid CASE value"))
+	}
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return &goast.BinaryExpr{X: conds[0], Op: token.LAND, Y: conds[1]}
+}
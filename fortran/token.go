@@ -52,6 +52,23 @@ const (
 
 	ftInclude
 
+	ftExit
+	ftCycle
+	ftPause
+
+	ftModule
+	ftUse
+	ftContains
+
+	ftInterface
+	ftDefinedOp
+
+	ftNamelist
+	ftInquire
+	ftBackspace
+	ftEndfile
+	ftPrint
+
 	// undefine tokens
 	ftUndefine
 )
@@ -112,5 +129,22 @@ var o = [...]string{
 
 	ftInclude: "INCLUDE",
 
+	ftExit:  "EXIT",
+	ftCycle: "CYCLE",
+	ftPause: "PAUSE",
+
+	ftModule:   "MODULE",
+	ftUse:      "USE",
+	ftContains: "CONTAINS",
+
+	ftInterface: "INTERFACE",
+	ftDefinedOp: "DEFINED_OPERATOR",
+
+	ftNamelist:  "NAMELIST",
+	ftInquire:   "INQUIRE",
+	ftBackspace: "BACKSPACE",
+	ftEndfile:   "ENDFILE",
+	ftPrint:     "PRINT",
+
 	ftUndefine: "UNDEFINE",
 }
@@ -16,6 +16,14 @@ func (g goType) isArray() bool {
 type goType struct {
 	baseType  string
 	arrayNode [][]node
+
+	allocatable bool // array with attribute ALLOCATABLE
+	module      bool // package-level variable of MODULE
+	host        bool // variable of host procedure
+	constant    bool // named constant translated to Go constant
+	optional    bool // OPTIONAL dummy argument, nil if absent
+	value       bool // INTENT(IN) scalar argument of module or internal procedure
+	single      bool // REAL or COMPLEX of single precision
 }
 
 func (g goType) getMinLimit(col int) (size int, ok bool) {
@@ -152,6 +160,7 @@ func parseType(nodes []node) (typ goType) {
 	case ftComplex:
 		// COMPLEX or COMPLEX * 8
 		typ.baseType = "complex128" // TODO: for minimaze type convection "complex64"
+		typ.single = true
 		nodes = nodes[1:]
 		if len(nodes) > 1 &&
 			nodes[0].tok == token.MUL &&
@@ -161,6 +170,7 @@ func parseType(nodes []node) (typ goType) {
 				typ.baseType = "complex128" // TODO: for minimaze type convection "complex64"
 			case "16": // COMPLEX * 16
 				typ.baseType = "complex128"
+				typ.single = false
 			default:
 				// COMPLEX * 32
 				panic(fmt.Errorf(
@@ -185,6 +195,7 @@ func parseType(nodes []node) (typ goType) {
 	case ftReal:
 		// REAL or REAL * 4
 		typ.baseType = "float64" // TODO : correct type "float32"
+		typ.single = true
 		nodes = nodes[1:]
 		if len(nodes) > 1 &&
 			nodes[0].tok == token.MUL &&
@@ -194,6 +205,7 @@ func parseType(nodes []node) (typ goType) {
 				typ.baseType = "float64" // TODO: for minimaze type convection "float32"
 			case "8": // REAL * 8
 				typ.baseType = "float64"
+				typ.single = false
 			default:
 				// REAL * 16
 				panic(fmt.Errorf(
@@ -257,9 +269,22 @@ func parseType(nodes []node) (typ goType) {
 
 	args, end := separateArgsParen(nodes)
 
+	// length of CHARACTER is last dimension in order of Fortran,
+	// so String returns dimensions in reverse order
+	// Example:
+	//  CHARACTER * 8 (3)
+	// Dimensions:
+	//  3, 8
+	// String:
+	//  [8][3]byte
+	// Go code is 3 elements with 8 bytes.
+	length := typ.arrayNode
+	typ.arrayNode = nil
+
 	for _, a := range args {
 		typ.arrayNode = append(typ.arrayNode, a)
 	}
+	typ.arrayNode = append(typ.arrayNode, length...)
 
 	nodes = nodes[end:]
 	return
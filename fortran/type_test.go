@@ -34,6 +34,18 @@ func TestParseType(t *testing.T) {
 			},
 			typ: "[32]byte",
 		},
+		// CHARACTER*8 ROWS(3)
+		{
+			nodes: []node{
+				{tok: ftCharacter, b: []byte("CHARACTER")},
+				{tok: token.MUL, b: []byte("*")},
+				{tok: token.INT, b: []byte("8")},
+				{tok: token.LPAREN, b: []byte("(")},
+				{tok: token.INT, b: []byte("3")},
+				{tok: token.RPAREN, b: []byte(")")},
+			},
+			typ: "[8][3]byte",
+		},
 		{
 			nodes: []node{
 				{tok: ftInteger, b: []byte("INTEGER")},
@@ -127,7 +127,8 @@ func (p *parser) split(nodes *[]node, pos int) (
 			token.LEQ, // <=
 			token.GEQ, // >=
 
-			ftDoubleStar, // **
+			ftDoubleStar,   // **
+			ftStringConcat, // //
 
 			token.COMMA, // ,
 
@@ -0,0 +1,133 @@
+package intrinsic
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// sliceOf return value of slice by pointer to slice
+func sliceOf(array interface{}) reflect.Value {
+	v := reflect.ValueOf(array)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Errorf("not valid array: %#v", array))
+	}
+	return v
+}
+
+// makeArray return slice of slices with extents of dimensions
+func makeArray(t reflect.Type, extents []int) reflect.Value {
+	size := extents[0]
+	if size < 0 {
+		size = 0
+	}
+	s := reflect.MakeSlice(t, size, size)
+	if len(extents) > 1 {
+		for i := 0; i < size; i++ {
+			s.Index(i).Set(makeArray(t.Elem(), extents[1:]))
+		}
+	}
+	return s
+}
+
+// shape return extents of all dimensions of allocated array
+func shape(v reflect.Value) (extents []int) {
+	for v.Kind() == reflect.Slice {
+		extents = append(extents, v.Len())
+		if v.Len() == 0 {
+			break
+		}
+		v = v.Index(0)
+	}
+	return
+}
+
+// ALLOCATE allocates array with extents of dimensions.
+// Argument array is pointer to slice.
+// If stat is nil, then error of allocation terminates program,
+// otherwise stat is zero for successful allocation.
+func ALLOCATE(stat *int, array interface{}, extents ...int) {
+	v := reflect.ValueOf(array)
+	if v.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("array is not pointer: %#v", array))
+	}
+	v = v.Elem()
+	if !v.IsNil() {
+		if stat == nil {
+			runtimeError("Attempting to allocate already allocated variable")
+		}
+		*stat = 1
+		return
+	}
+	v.Set(makeArray(v.Type(), extents))
+	if stat != nil {
+		*stat = 0
+	}
+}
+
+// REALLOCATE allocates array again, if extents of dimensions are
+// not same. Used for assignment to allocatable array.
+// Return true, if array is allocated again.
+func REALLOCATE(array interface{}, extents ...int) bool {
+	v := reflect.ValueOf(array).Elem()
+	if !v.IsNil() {
+		s := shape(v)
+		equal := len(s) == len(extents)
+		for i := 0; equal && i < len(s); i++ {
+			equal = s[i] == extents[i]
+		}
+		if equal {
+			return false
+		}
+	}
+	v.Set(makeArray(v.Type(), extents))
+	return true
+}
+
+// DEALLOCATE deallocates array.
+// Argument array is pointer to slice.
+// If stat is nil, then error of deallocation terminates program,
+// otherwise stat is zero for successful deallocation.
+func DEALLOCATE(stat *int, array interface{}) {
+	v := reflect.ValueOf(array)
+	if v.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("array is not pointer: %#v", array))
+	}
+	v = v.Elem()
+	if v.IsNil() {
+		if stat == nil {
+			runtimeError("Attempt to DEALLOCATE unallocated variable")
+		}
+		*stat = 1
+		return
+	}
+	v.Set(reflect.Zero(v.Type()))
+	if stat != nil {
+		*stat = 0
+	}
+}
+
+// ALLOCATED return true for allocated array
+func ALLOCATED(array interface{}) bool {
+	return !sliceOf(array).IsNil()
+}
+
+// SIZE return extent of array along dimension dim.
+// Without dimension return amount of elements.
+func SIZE(array interface{}, dim ...int) int {
+	s := shape(sliceOf(array))
+	if len(dim) > 0 {
+		if dim[0] < 1 || dim[0] > len(s) {
+			// dimensions after zero-sized dimension
+			return 0
+		}
+		return s[dim[0]-1]
+	}
+	size := 1
+	for _, e := range s {
+		size *= e
+	}
+	return size
+}
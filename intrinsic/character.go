@@ -0,0 +1,76 @@
+package intrinsic
+
+import "fmt"
+
+// castToBytes return character value as slice of bytes
+func castToBytes(w interface{}) []byte {
+	switch v := w.(type) {
+	case []byte:
+		return v
+	case *[]byte:
+		return *v
+	case byte:
+		return []byte{v}
+	case *byte:
+		return []byte{*v}
+	case rune:
+		return []byte{byte(v)}
+	case string:
+		return []byte(v)
+	default:
+		panic(fmt.Errorf("cannot cast to character: %#v", w))
+	}
+}
+
+// compareCharacter compare character values in ASCII collating sequence.
+// Shorter value is extended with blanks.
+func compareCharacter(a, b interface{}) int {
+	A := castToBytes(a)
+	B := castToBytes(b)
+	size := len(A)
+	if len(B) > size {
+		size = len(B)
+	}
+	for i := 0; i < size; i++ {
+		var ca, cb byte = ' ', ' '
+		if i < len(A) {
+			ca = A[i]
+		}
+		if i < len(B) {
+			cb = B[i]
+		}
+		if ca < cb {
+			return -1
+		}
+		if ca > cb {
+			return 1
+		}
+	}
+	return 0
+}
+
+// CONCAT return concatenation of character values.
+//
+// Fortran:
+//  A // B
+// Go code:
+//  intrinsic.CONCAT(A, B)
+func CONCAT(a, b interface{}) []byte {
+	return append(append([]byte{}, castToBytes(a)...), castToBytes(b)...)
+}
+
+func LGE(a, b interface{}) bool {
+	return compareCharacter(a, b) >= 0
+}
+
+func LGT(a, b interface{}) bool {
+	return compareCharacter(a, b) > 0
+}
+
+func LLE(a, b interface{}) bool {
+	return compareCharacter(a, b) <= 0
+}
+
+func LLT(a, b interface{}) bool {
+	return compareCharacter(a, b) < 0
+}
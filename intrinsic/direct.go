@@ -0,0 +1,145 @@
+package intrinsic
+
+import (
+	"bytes"
+	"io"
+)
+
+// Direct access file of gfortran is sequence of records with length
+// RECL in bytes. Records have no markers and no newlines.
+// Formatted record is filled by blanks, unformatted record is filled
+// by zeros.
+
+// control is unit with specifiers of data transfer statement
+type control struct {
+	unit interface{}
+	sp   map[string]interface{}
+}
+
+// CONTROL return unit with specifiers of data transfer statement.
+// Arguments are pairs of name of specifier and value.
+//
+// Fortran:
+//  READ(10, REC=K) A
+// Go code:
+//  intrinsic.READ(intrinsic.CONTROL("UNIT", 10, "REC", (*K)), nil, A)
+func CONTROL(a ...interface{}) interface{} {
+	sp := specifiers(a)
+	return &control{unit: sp["UNIT"], sp: sp}
+}
+
+// controlList return unit and specifiers of data transfer statement
+func controlList(unit interface{}) (interface{}, map[string]interface{}) {
+	if c, ok := unit.(*control); ok {
+		return c.unit, c.sp
+	}
+	return unit, map[string]interface{}{}
+}
+
+// record return number of record for data transfer.
+// Zero is returned for sequential access.
+func (u *unitFile) record(sp map[string]interface{}) (int, *ioError) {
+	v, ok := sp["REC"]
+	switch {
+	case u.access == "DIRECT" && !ok:
+		return 0, newIOError(iostatOptionConflict,
+			"Direct access data transfer requires record number")
+	case u.access != "DIRECT" && ok:
+		return 0, newIOError(iostatOptionConflict,
+			"Record number not allowed for sequential access data transfer")
+	case !ok:
+		return 0, nil
+	}
+	rec, ok := unitNumber(v)
+	if !ok || rec <= 0 {
+		return 0, newIOError(iostatBadOption, "Record number must be positive")
+	}
+	return rec, nil
+}
+
+// writeRecord write record with number rec of direct access file.
+// Rest of record is filled by value fill.
+func (u *unitFile) writeRecord(rec int, data []byte, fill byte) *ioError {
+	if len(data) > u.recl {
+		return newIOError(iostatDirectEOR,
+			"Write exceeds length of DIRECT access record")
+	}
+	record := make([]byte, u.recl)
+	n := copy(record, data)
+	for ; n < len(record); n++ {
+		record[n] = fill
+	}
+	if _, err := u.file.WriteAt(record, int64(rec-1)*int64(u.recl)); err != nil {
+		return osError(err, "Cannot write to file '%s'", u.name)
+	}
+	u.nextrec = rec + 1
+	return nil
+}
+
+// readRecord read record with number rec of direct access file.
+// Not complete last record of file is filled by value fill.
+func (u *unitFile) readRecord(rec int, fill byte) ([]byte, *ioError) {
+	record := make([]byte, u.recl)
+	n, err := u.file.ReadAt(record, int64(rec-1)*int64(u.recl))
+	if n == 0 && err == io.EOF {
+		return nil, newIOError(iostatEnd, "End of file")
+	}
+	if err != nil && err != io.EOF {
+		return nil, osError(err, "Cannot read from file '%s'", u.name)
+	}
+	for ; n < len(record); n++ {
+		record[n] = fill
+	}
+	u.nextrec = rec + 1
+	return record, nil
+}
+
+// directFile is output of formatted direct access file.
+// Each line of output is written in next record.
+type directFile struct {
+	u   *unitFile
+	rec int
+	buf bytes.Buffer
+}
+
+func (f *directFile) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+// flush write lines of output in records
+func (f *directFile) flush() *ioError {
+	out := bytes.TrimSuffix(f.buf.Bytes(), []byte("\n"))
+	for i, line := range bytes.Split(out, []byte("\n")) {
+		if e := f.u.writeRecord(f.rec+i, line, ' '); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// directReader is input of formatted direct access file.
+// Each record is ended by newline.
+type directReader struct {
+	u      *unitFile
+	rec    int
+	record []byte // rest of current record
+}
+
+// Read return rest of current record. Next record is read only
+// after end of current record.
+func (r *directReader) Read(p []byte) (int, error) {
+	if len(r.record) == 0 {
+		record, e := r.u.readRecord(r.rec, ' ')
+		if e != nil && e.code == iostatEnd {
+			return 0, io.EOF
+		}
+		if e != nil {
+			return 0, e
+		}
+		r.record = append(record, '\n')
+		r.rec++
+	}
+	n := copy(p, r.record)
+	r.record = r.record[n:]
+	return n, nil
+}
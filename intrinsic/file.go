@@ -0,0 +1,522 @@
+package intrinsic
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// specifiers return map of specifiers of input/output statement.
+// Arguments are pairs of name and value.
+//
+// Example:
+//  specifiers([]interface{}{"UNIT", 10, "EXIST", &exist})
+func specifiers(a []interface{}) map[string]interface{} {
+	if len(a)%2 != 0 {
+		panic(fmt.Errorf("not valid specifiers: %v", a))
+	}
+	m := map[string]interface{}{}
+	for i := 0; i < len(a); i += 2 {
+		m[strings.ToUpper(string(castToBytes(a[i])))] = a[i+1]
+	}
+	return m
+}
+
+// setSpecifier store value in variable of specifier.
+// Character variable is filled by blanks.
+func setSpecifier(variable interface{}, value interface{}) {
+	switch v := variable.(type) {
+	case *bool:
+		*v = value.(bool)
+	case *int:
+		*v = value.(int)
+	case *[]byte:
+		b := []byte(value.(string))
+		n := copy(*v, b)
+		for ; n < len(*v); n++ {
+			(*v)[n] = ' '
+		}
+	case *byte:
+		*v = ' '
+		if s := value.(string); s != "" {
+			*v = s[0]
+		}
+	default:
+		panic(fmt.Errorf("not valid type of specifier: %T", variable))
+	}
+}
+
+// option return value of character specifier in upper case.
+// If specifier is not present, then return default value.
+func option(sp map[string]interface{}, key, def string) string {
+	v, ok := sp[key]
+	if !ok {
+		return def
+	}
+	return strings.ToUpper(strings.TrimSpace(string(castToBytes(v))))
+}
+
+// oneOf return true if value is in list
+func oneOf(value string, list ...string) bool {
+	for _, l := range list {
+		if value == l {
+			return true
+		}
+	}
+	return false
+}
+
+// unitOfFile return number and state of unit connected to file
+func (rt *Runtime) unitOfFile(name string) (int, *unitFile) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return -1, nil
+	}
+	for n, u := range rt.units {
+		if u.name == "" {
+			continue
+		}
+		if ui, err := os.Stat(u.name); err == nil && os.SameFile(info, ui) {
+			return n, u
+		}
+	}
+	return -1, nil
+}
+
+// actionFlag is flag of opening file for action
+var actionFlag = map[string]int{
+	"READ":      os.O_RDONLY,
+	"WRITE":     os.O_WRONLY,
+	"READWRITE": os.O_RDWR,
+}
+
+// OPEN connect unit to file and return status of statement.
+// Arguments are pairs of name of specifier and value.
+// Value of specifier IOSTAT is pointer to variable.
+//
+// Fortran:
+//  OPEN(UNIT=10, FILE='out.txt', STATUS='REPLACE', IOSTAT=IOS)
+// Go code:
+//  intrinsic.OPEN("UNIT", 10, "FILE", []byte("out.txt"), "STATUS", []byte("REPLACE"), "IOSTAT", IOS)
+func (rt *Runtime) OPEN(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+	return rt.status(sp, rt.open(sp))
+}
+
+func (rt *Runtime) open(sp map[string]interface{}) *ioError {
+	n, ok := unitNumber(sp["UNIT"])
+	if !ok || n < 0 {
+		return newIOError(iostatBadUnit, "Bad unit number in OPEN statement")
+	}
+
+	u := &unitFile{
+		access: option(sp, "ACCESS", "SEQUENTIAL"),
+		action: option(sp, "ACTION", ""),
+	}
+	st := option(sp, "STATUS", "UNKNOWN")
+	position := option(sp, "POSITION", "ASIS")
+	if u.access == "APPEND" {
+		// extension of gfortran
+		u.access, position = "SEQUENTIAL", "APPEND"
+	}
+	form := "FORMATTED"
+	if u.access == "DIRECT" {
+		form = "UNFORMATTED"
+	}
+	u.form = option(sp, "FORM", form)
+	convert := option(sp, "CONVERT", "NATIVE")
+
+	switch {
+	case !oneOf(st, "OLD", "NEW", "REPLACE", "SCRATCH", "UNKNOWN"):
+		return newIOError(iostatBadOption, "Bad STATUS parameter in OPEN statement")
+	case !oneOf(u.access, "SEQUENTIAL", "DIRECT"):
+		return newIOError(iostatBadOption, "Bad ACCESS parameter in OPEN statement")
+	case !oneOf(u.form, "FORMATTED", "UNFORMATTED"):
+		return newIOError(iostatBadOption, "Bad FORM parameter in OPEN statement")
+	case !oneOf(u.action, "", "READ", "WRITE", "READWRITE"):
+		return newIOError(iostatBadOption, "Bad ACTION parameter in OPEN statement")
+	case !oneOf(position, "ASIS", "REWIND", "APPEND"):
+		return newIOError(iostatBadOption, "Bad POSITION parameter in OPEN statement")
+	}
+	if u.order, ok = convertOrder(convert); !ok {
+		return newIOError(iostatBadOption, "Bad CONVERT parameter in OPEN statement")
+	}
+
+	u.recl = defaultRecl
+	if u.access == "DIRECT" {
+		u.nextrec = 1
+	}
+	if v, ok := sp["RECL"]; ok {
+		if u.recl, ok = unitNumber(v); !ok || u.recl <= 0 {
+			return newIOError(iostatBadOption,
+				"RECL parameter is non-positive in OPEN statement")
+		}
+	} else if u.access == "DIRECT" {
+		return newIOError(iostatMissingOption,
+			"Missing RECL parameter in OPEN statement")
+	}
+
+	// name of file
+	if v, ok := sp["FILE"]; ok {
+		if st == "SCRATCH" {
+			return newIOError(iostatOptionConflict,
+				"FILE parameter must not be present in OPEN statement")
+		}
+		u.name = strings.TrimRight(string(castToBytes(v)), " ")
+	} else if st != "SCRATCH" {
+		u.name = fmt.Sprintf("fort.%d", n)
+	}
+
+	switch m, _ := rt.unitOfFile(u.name); {
+	case m == n:
+		// unit is connected to same file
+		return nil
+	case 0 <= m:
+		return newIOError(iostatAlreadyOpen,
+			"File already opened in another unit")
+	}
+	if _, ok := rt.units[n]; ok {
+		// unit is connected to other file
+		if e := rt.closeUnit(n, ""); e != nil {
+			return e
+		}
+	}
+
+	flag := os.O_CREATE
+	switch st {
+	case "OLD":
+		flag = 0
+	case "NEW":
+		flag |= os.O_EXCL
+	case "REPLACE":
+		flag |= os.O_TRUNC
+	}
+
+	var err error
+	switch {
+	case st == "SCRATCH":
+		var f *os.File
+		if f, err = ioutil.TempFile("", "gfortrantmp"); err == nil {
+			u.file, u.name, u.scratch = f, f.Name(), true
+		}
+		if u.action == "" {
+			u.action = "READWRITE"
+		}
+	case u.action != "":
+		u.file, err = os.OpenFile(u.name, flag|actionFlag[u.action], 0666)
+	default:
+		// try all actions like gfortran
+		for _, action := range []string{"READWRITE", "READ", "WRITE"} {
+			u.file, err = os.OpenFile(u.name, flag|actionFlag[action], 0666)
+			if err == nil {
+				u.action = action
+			}
+			if !os.IsPermission(err) {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return osError(err, "Cannot open file '%s'", u.name)
+	}
+	if position == "APPEND" {
+		if _, err := u.file.Seek(0, io.SeekEnd); err != nil {
+			u.file.Close()
+			return osError(err, "Cannot open file '%s'", u.name)
+		}
+	}
+	rt.units[n] = u
+	return nil
+}
+
+// CLOSE disconnect unit and return status of statement.
+// Arguments are pairs of name of specifier and value.
+//
+// Fortran:
+//  CLOSE(10, STATUS='DELETE')
+// Go code:
+//  intrinsic.CLOSE("UNIT", 10, "STATUS", []byte("DELETE"))
+func (rt *Runtime) CLOSE(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+	n, ok := unitNumber(sp["UNIT"])
+	if !ok || n < 0 {
+		return rt.status(sp, newIOError(iostatBadUnit,
+			"Bad unit number in CLOSE statement"))
+	}
+	st := option(sp, "STATUS", "")
+	if !oneOf(st, "", "KEEP", "DELETE") {
+		return rt.status(sp, newIOError(iostatBadOption,
+			"Bad STATUS parameter in CLOSE statement"))
+	}
+	return rt.status(sp, rt.closeUnit(n, st))
+}
+
+// closeUnit disconnect unit with status KEEP or DELETE.
+// Scratch file is deleted by default.
+// Not connected unit is ignored.
+func (rt *Runtime) closeUnit(n int, st string) *ioError {
+	u, ok := rt.units[n]
+	if !ok {
+		return nil
+	}
+	if u.scratch && st == "KEEP" {
+		return newIOError(iostatBadOption,
+			"Can't KEEP a scratch file on CLOSE")
+	}
+	delete(rt.units, n)
+	if u.name == "" {
+		// preconnected unit
+		return nil
+	}
+	if err := u.file.Close(); err != nil {
+		return osError(err, "Cannot close file '%s'", u.name)
+	}
+	if st == "DELETE" || u.scratch {
+		if err := os.Remove(u.name); err != nil {
+			return osError(err, "Cannot delete file '%s'", u.name)
+		}
+	}
+	return nil
+}
+
+// REWIND positions external unit at initial point of file and return
+// status of statement. Arguments are pairs of name of specifier and value.
+//
+// Fortran:
+//  REWIND(10, IOSTAT=IOS)
+// Go code:
+//  intrinsic.REWIND("UNIT", 10, "IOSTAT", IOS)
+func (rt *Runtime) REWIND(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+	u, e := rt.connected(sp["UNIT"], "")
+	if e != nil {
+		return rt.status(sp, e)
+	}
+	if err := u.seek(0); err != nil {
+		return rt.status(sp, osError(err, "Cannot REWIND file '%s'", u.name))
+	}
+	u.endfile = false
+	return rt.status(sp, nil)
+}
+
+// BACKSPACE positions external unit before preceding record and return
+// status of statement. If there is no preceding record, then position
+// is not changed. Arguments are same as for REWIND.
+func (rt *Runtime) BACKSPACE(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+	u, e := rt.connected(sp["UNIT"], "")
+	if e == nil {
+		e = backspace(u)
+	}
+	return rt.status(sp, e)
+}
+
+func backspace(u *unitFile) *ioError {
+	fail := func(err error) *ioError {
+		return osError(err, "Cannot BACKSPACE file '%s'", u.name)
+	}
+	if u.endfile {
+		// position before endfile record
+		u.endfile = false
+		return nil
+	}
+	pos, err := u.tell()
+	if err != nil {
+		return fail(err)
+	}
+	if pos == 0 {
+		return nil
+	}
+	if u.form == "UNFORMATTED" {
+		if pos, err = u.backspaceUnformatted(pos); err == nil {
+			err = u.seek(pos)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		return nil
+	}
+
+	// find end of record before preceding record
+	const size = 4096
+	end := pos - 1 // newline of preceding record
+	start := int64(0)
+	buf := make([]byte, size)
+	for end > 0 {
+		from := end - size
+		if from < 0 {
+			from = 0
+		}
+		n, err := u.file.ReadAt(buf[:end-from], from)
+		if err != nil && err != io.EOF {
+			return fail(err)
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			start = from + int64(i) + 1
+			break
+		}
+		end = from
+	}
+	if err := u.seek(start); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// ENDFILE writes endfile record and return status of statement.
+// File is truncated at current position. Arguments are same as
+// for REWIND.
+func (rt *Runtime) ENDFILE(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+	u, e := rt.connected(sp["UNIT"], "")
+	if e != nil {
+		return rt.status(sp, e)
+	}
+	pos, err := u.tell()
+	if err == nil {
+		err = u.seek(pos)
+	}
+	if err == nil {
+		err = u.file.Truncate(pos)
+	}
+	if err != nil {
+		return rt.status(sp, osError(err, "Cannot ENDFILE file '%s'", u.name))
+	}
+	u.endfile = true
+	return rt.status(sp, nil)
+}
+
+// INQUIRE store properties of unit or file and return status of statement.
+// Arguments are pairs of name of specifier and value.
+// Value of specifiers UNIT and FILE is unit and name of file,
+// other values are pointers to variables.
+//
+// Fortran:
+//  INQUIRE(FILE='data.txt', EXIST=EX, OPENED=OP)
+// Go code:
+//  intrinsic.INQUIRE("FILE", []byte("data.txt"), "EXIST", EX, "OPENED", OP)
+func (rt *Runtime) INQUIRE(a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	sp := specifiers(a)
+
+	var (
+		u     *unitFile
+		exist bool
+		name  string
+		num   = -1
+	)
+	if v, ok := sp["UNIT"]; ok {
+		n, ok := unitNumber(v)
+		if !ok {
+			return rt.status(sp, newIOError(iostatBadUnit,
+				"Bad unit number in INQUIRE statement"))
+		}
+		exist = 0 <= n
+		if u = rt.units[n]; u != nil {
+			num = n
+			name = u.name
+		}
+	}
+	if v, ok := sp["FILE"]; ok {
+		name = strings.TrimRight(string(castToBytes(v)), " ")
+		_, err := os.Stat(name)
+		exist = err == nil
+		num, u = rt.unitOfFile(name)
+	}
+	// properties of not connected unit are empty
+	c := u
+	if c == nil {
+		c = new(unitFile)
+	}
+
+	// value of specifier for connected and not connected unit
+	choose := func(opened, other string) string {
+		if u != nil {
+			return opened
+		}
+		return other
+	}
+	yes := func(ok bool) string {
+		if ok {
+			return "YES"
+		}
+		return "NO"
+	}
+
+	for key, variable := range sp {
+		var value interface{}
+		switch key {
+		case "UNIT", "FILE", "IOSTAT", "IOMSG", "ERR":
+			continue
+		case "EXIST":
+			value = exist
+		case "OPENED":
+			value = u != nil
+		case "NUMBER":
+			value = num
+		case "NAMED":
+			value = name != ""
+		case "NAME":
+			value = name
+		case "ACCESS":
+			value = choose(c.access, "UNDEFINED")
+		case "SEQUENTIAL":
+			value = choose(yes(c.access == "SEQUENTIAL"), "UNKNOWN")
+		case "DIRECT":
+			value = choose(yes(c.access == "DIRECT"), "UNKNOWN")
+		case "FORM":
+			value = choose(c.form, "UNDEFINED")
+		case "FORMATTED":
+			value = choose(yes(c.form == "FORMATTED"), "UNKNOWN")
+		case "UNFORMATTED":
+			value = choose(yes(c.form == "UNFORMATTED"), "UNKNOWN")
+		case "RECL":
+			value = -1
+			if u != nil {
+				value = u.recl
+			}
+		case "NEXTREC":
+			value = c.nextrec
+		case "POSITION":
+			value = "UNDEFINED"
+			if u != nil {
+				value = "ASIS"
+				if pos, err := u.tell(); err == nil && pos == 0 {
+					value = "REWIND"
+				}
+			}
+		case "SIZE":
+			value = -1
+			if info, err := os.Stat(name); err == nil && name != "" {
+				value = int(info.Size())
+			}
+		case "ACTION":
+			value = choose(c.action, "UNDEFINED")
+		case "READ":
+			value = choose(yes(strings.Contains(c.action, "READ")), "UNKNOWN")
+		case "WRITE":
+			value = choose(yes(strings.Contains(c.action, "WRITE")), "UNKNOWN")
+		case "READWRITE":
+			value = choose(yes(c.action == "READWRITE"), "UNKNOWN")
+		default:
+			return rt.status(sp, newIOError(iostatBadOption,
+				"Specifier %s is not supported in INQUIRE", key))
+		}
+		setSpecifier(variable, value)
+	}
+	return rt.status(sp, nil)
+}
@@ -0,0 +1,298 @@
+package intrinsic
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// formatItem is item of format specification
+type formatItem struct {
+	repeat int          // repeat count
+	code   string       // edit descriptor, "'" for string, "(" for group
+	w, d   int          // width and digits, -1 if absent
+	e      int          // digits of exponent, -1 if absent
+	str    string       // character string
+	group  []formatItem // items of group
+}
+
+// format is parsed format specification
+type format struct {
+	items []formatItem
+
+	// index of item for format reversion. Reversion is started from
+	// last group of top level or from begin of format.
+	reversion int
+}
+
+// formats is cache of parsed format specifications
+var formats = struct {
+	sync.Mutex
+	m map[string]*format
+}{m: map[string]*format{}}
+
+// cachedFormat return parsed format specification.
+// Each format specification is parsed only once.
+func cachedFormat(text []byte) (*format, *ioError) {
+	formats.Lock()
+	defer formats.Unlock()
+	if f, ok := formats.m[string(text)]; ok {
+		return f, nil
+	}
+	f, e := parseFormat(string(text))
+	if e != nil {
+		return nil, e
+	}
+	formats.m[string(text)] = f
+	return f, nil
+}
+
+// formatParser is parser of format specification.
+// Blanks are ignored outside character strings.
+type formatParser struct {
+	s   string
+	pos int
+}
+
+// error return error with position in format specification
+func (p *formatParser) error(msg string) *ioError {
+	return newIOError(iostatFormat, "%s\n\n%s\n%s^",
+		msg, p.s, strings.Repeat(" ", p.pos))
+}
+
+// peek return upper case of next character of format specification
+// or zero at the end
+func (p *formatParser) peek() byte {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos == len(p.s) {
+		return 0
+	}
+	c := p.s[p.pos]
+	if 'a' <= c && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
+// number return unsigned integer number
+func (p *formatParser) number() (n int, ok bool) {
+	for c := p.peek(); '0' <= c && c <= '9'; c = p.peek() {
+		n = n*10 + int(c-'0')
+		ok = true
+		p.pos++
+	}
+	return
+}
+
+// parseFormat return parsed format specification.
+// Example:
+//  (1X, 'A = ', 3(I5, 2X), /, 1PE12.4, T40, SP, G12.4E3)
+func parseFormat(s string) (*format, *ioError) {
+	p := &formatParser{s: s}
+	if p.peek() != '(' {
+		return nil, p.error("Missing initial left parenthesis in format")
+	}
+	p.pos++
+	items, e := p.list()
+	if e != nil {
+		return nil, e
+	}
+	f := &format{items: items}
+	for i := range items {
+		if items[i].code == "(" {
+			f.reversion = i
+		}
+	}
+	return f, nil
+}
+
+// hasData return true, if items contain data edit descriptor
+func hasData(items []formatItem) bool {
+	for _, it := range items {
+		switch it.code {
+		case "(":
+			if hasData(it.group) {
+				return true
+			}
+		case "I", "F", "E", "D", "G", "A", "L", "B", "O", "Z":
+			return true
+		}
+	}
+	return false
+}
+
+// list return items of format until right parenthesis.
+// Commas between items are optional.
+func (p *formatParser) list() (items []formatItem, e *ioError) {
+	for {
+		switch p.peek() {
+		case 0:
+			return nil, p.error("Missing right parenthesis in format")
+		case ')':
+			p.pos++
+			return items, nil
+		case ',':
+			p.pos++
+			continue
+		}
+		it, e := p.item()
+		if e != nil {
+			return nil, e
+		}
+		items = append(items, it)
+	}
+}
+
+// item return one item of format
+func (p *formatParser) item() (it formatItem, e *ioError) {
+	it = formatItem{repeat: 1, w: -1, d: -1, e: -1}
+
+	sign := 1
+	switch p.peek() {
+	case '-':
+		sign = -1
+		p.pos++
+	case '+':
+		p.pos++
+	}
+	n, hasN := p.number()
+	if hasN && n == 0 && p.peek() != 'P' {
+		return it, p.error("Zero repeat count in format")
+	}
+	if hasN {
+		it.repeat = n
+	}
+
+	c := p.peek()
+	if sign < 0 && c != 'P' {
+		return it, p.error("Expected P edit descriptor in format")
+	}
+	switch c {
+	case 0:
+		return it, p.error("Missing right parenthesis in format")
+	case '\'', '"':
+		if hasN {
+			return it, p.error("Repeat count of character string in format")
+		}
+		it.code = "'"
+		it.str, e = p.str(p.s[p.pos])
+		return
+	}
+	p.pos++
+
+	switch c {
+	case '(':
+		it.code = "("
+		it.group, e = p.list()
+
+	case 'H':
+		// Hollerith constant
+		if !hasN || p.pos+n > len(p.s) {
+			return it, p.error("Not valid Hollerith constant in format")
+		}
+		it.code, it.repeat = "'", 1
+		it.str = p.s[p.pos : p.pos+n]
+		p.pos += n
+
+	case 'P':
+		// scale factor
+		if !hasN {
+			return it, p.error("Expected integer before P edit descriptor in format")
+		}
+		it.code, it.repeat = "P", 1
+		it.w = sign * n
+
+	case 'X', '/':
+		// X is n positions, / is n records
+		it.code = string(c)
+
+	case ':':
+		it.code = ":"
+
+	case 'T':
+		// tabulation T, TL or TR
+		it.code = "T"
+		if c := p.peek(); c == 'L' || c == 'R' {
+			it.code += string(c)
+			p.pos++
+		}
+		var ok bool
+		if it.w, ok = p.number(); !ok || (it.w == 0 && it.code == "T") {
+			return it, p.error("Positive width required with T descriptor")
+		}
+		it.repeat = 1
+
+	case 'S':
+		// sign control S, SP or SS
+		it.code = "S"
+		if c := p.peek(); c == 'P' || c == 'S' {
+			it.code += string(c)
+			p.pos++
+		}
+		it.repeat = 1
+
+	case 'B':
+		// blank control BN or BZ
+		if c := p.peek(); c == 'N' || c == 'Z' {
+			it.code, it.repeat = "B"+string(c), 1
+			p.pos++
+			break
+		}
+		fallthrough
+
+	case 'I', 'F', 'E', 'D', 'G', 'A', 'L', 'O', 'Z':
+		it.code = string(c)
+		var ok bool
+		if it.w, ok = p.number(); !ok {
+			it.w = -1
+		}
+		if p.peek() == '.' {
+			p.pos++
+			if it.d, ok = p.number(); !ok {
+				return it, p.error("Nonnegative width required in format")
+			}
+			if (c == 'E' || c == 'G') && p.peek() == 'E' {
+				p.pos++
+				if it.e, ok = p.number(); !ok || it.e == 0 {
+					return it, p.error("Positive exponent width required in format")
+				}
+			}
+		}
+		switch {
+		case c == 'A' && it.w == 0:
+			return it, p.error("Positive width required in format")
+		case c == 'A':
+		case it.w < 0 || (it.w == 0 && bytes.IndexByte([]byte("IFGBOZ"), c) < 0):
+			return it, p.error("Positive width required in format")
+		case (c == 'F' || c == 'E' || c == 'D') && it.d < 0:
+			return it, p.error("Period required in format specifier")
+		}
+
+	default:
+		p.pos--
+		return it, p.error("Unexpected element '" + string(p.s[p.pos]) + "' in format")
+	}
+	return
+}
+
+// str return character string with quote. Inside of string quote is
+// written twice.
+func (p *formatParser) str(quote byte) (string, *ioError) {
+	var s []byte
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		if p.s[p.pos] != quote {
+			s = append(s, p.s[p.pos])
+			continue
+		}
+		if p.pos+1 < len(p.s) && p.s[p.pos+1] == quote {
+			s = append(s, quote)
+			p.pos++
+			continue
+		}
+		p.pos++
+		return string(s), nil
+	}
+	return "", p.error("Unterminated character constant in format")
+}
@@ -0,0 +1,721 @@
+package intrinsic
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// datum is element of input/output list. Value of type COMPLEX is pair
+// of data for real and imaginary parts.
+type datum struct {
+	v    reflect.Value
+	part int // 0 - value, 1 - real part, 2 - imaginary part
+}
+
+// dataList return elements of input/output list
+func dataList(a []interface{}) (list []datum) {
+	for _, item := range a {
+		for _, e := range elements(item) {
+			if k := e.Kind(); k == reflect.Complex64 || k == reflect.Complex128 {
+				list = append(list, datum{v: e, part: 1}, datum{v: e, part: 2})
+				continue
+			}
+			list = append(list, datum{v: e})
+		}
+	}
+	return
+}
+
+// typeName return name of Fortran type of datum
+func (d datum) typeName() string {
+	switch d.v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		return "REAL"
+	case reflect.Complex64, reflect.Complex128:
+		return "COMPLEX"
+	case reflect.Bool:
+		return "LOGICAL"
+	case reflect.Uint8, reflect.Slice, reflect.String:
+		return "CHARACTER"
+	}
+	return d.v.Type().String()
+}
+
+// float return value of REAL datum
+func (d datum) float() float64 {
+	switch d.part {
+	case 1:
+		return real(d.v.Complex())
+	case 2:
+		return imag(d.v.Complex())
+	}
+	return d.v.Float()
+}
+
+// setFloat change value of REAL datum
+func (d datum) setFloat(x float64) {
+	switch d.part {
+	case 1:
+		d.v.SetComplex(complex(x, imag(d.v.Complex())))
+	case 2:
+		d.v.SetComplex(complex(real(d.v.Complex()), x))
+	default:
+		d.v.SetFloat(x)
+	}
+}
+
+// isInteger return true for datum of type INTEGER
+func (d datum) isInteger() bool {
+	return d.typeName() == "INTEGER"
+}
+
+// character return value of CHARACTER datum
+func (d datum) character() (b []byte, ok bool) {
+	switch d.v.Kind() {
+	case reflect.Slice:
+		return d.v.Bytes(), true
+	case reflect.String:
+		return []byte(d.v.String()), true
+	case reflect.Uint8:
+		return []byte{byte(d.v.Uint())}, true
+	}
+	return nil, false
+}
+
+// formatted is state of formatted data transfer
+type formatted struct {
+	list   []datum
+	next   int    // index of next datum in list
+	scale  int    // scale factor
+	plus   bool   // optional plus sign is written, SP edit descriptor
+	zero   bool   // blanks in numeric input are zeros, BZ edit descriptor
+	record []byte // current record
+	pos    int    // position in current record
+
+	w io.Writer     // output
+	r *bufio.Reader // input
+}
+
+// run transfer data by format. Errors of data transfer are panics
+// with *ioError inside of state.
+func (t *formatted) run(f *format) (e *ioError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*ioError)
+			if !ok {
+				panic(r)
+			}
+			e = err
+		}
+	}()
+
+	if t.r != nil {
+		t.nextRecord()
+	}
+	if !t.execute(f.items) && t.next < len(t.list) {
+		// format reversion
+		if !hasData(f.items[f.reversion:]) {
+			return newIOError(iostatFormat,
+				"Insufficient data descriptors in format after reversion")
+		}
+		for t.next < len(t.list) {
+			t.nextRecord()
+			if t.execute(f.items[f.reversion:]) {
+				break
+			}
+		}
+	}
+	if t.w != nil {
+		t.endRecord()
+	}
+	return nil
+}
+
+// execute process items of format. Return true, if data transfer is
+// terminated by data edit descriptor or colon without data.
+func (t *formatted) execute(items []formatItem) bool {
+	for i := range items {
+		it := &items[i]
+		switch it.code {
+		case "(":
+			for r := 0; r < it.repeat; r++ {
+				if t.execute(it.group) {
+					return true
+				}
+			}
+		case "'":
+			if t.r != nil {
+				panic(newIOError(iostatFormat, "Constant string in input format"))
+			}
+			t.put([]byte(it.str))
+		case "X":
+			t.pos += it.repeat
+		case "T":
+			t.pos = it.w - 1
+		case "TL":
+			if t.pos -= it.w; t.pos < 0 {
+				t.pos = 0
+			}
+		case "TR":
+			t.pos += it.w
+		case "S", "SS":
+			t.plus = false
+		case "SP":
+			t.plus = true
+		case "BN":
+			t.zero = false
+		case "BZ":
+			t.zero = true
+		case "/":
+			for r := 0; r < it.repeat; r++ {
+				t.nextRecord()
+			}
+		case ":":
+			if t.next == len(t.list) {
+				return true
+			}
+		case "P":
+			t.scale = it.w
+		default:
+			for r := 0; r < it.repeat; r++ {
+				if t.next == len(t.list) {
+					return true
+				}
+				d := t.list[t.next]
+				t.next++
+				if t.r != nil {
+					t.input(it, d)
+				} else {
+					t.output(it, d)
+				}
+			}
+		}
+	}
+	return false
+}
+
+// nextRecord end current record and begin next record
+func (t *formatted) nextRecord() {
+	if t.w != nil {
+		t.endRecord()
+		return
+	}
+	line, e := nextLine(t.r)
+	if e != nil {
+		panic(e)
+	}
+	t.record = line
+	t.pos = 0
+}
+
+// endRecord write current record to output
+func (t *formatted) endRecord() {
+	if _, err := t.w.Write(append(t.record, '\n')); err != nil {
+		panic(osError(err, "Cannot write to file"))
+	}
+	t.record = t.record[:0]
+	t.pos = 0
+}
+
+// put write characters in current position of record. Skipped
+// positions are filled by blanks.
+func (t *formatted) put(b []byte) {
+	for len(t.record) < t.pos+len(b) {
+		t.record = append(t.record, ' ')
+	}
+	t.pos += copy(t.record[t.pos:], b)
+}
+
+// field return characters of input field with width w.
+// Short record is padded by blanks. Numeric field is terminated
+// by comma.
+func (t *formatted) field(w int, numeric bool) []byte {
+	b := make([]byte, w)
+	for i := range b {
+		b[i] = ' '
+		if t.pos+i < len(t.record) {
+			b[i] = t.record[t.pos+i]
+		}
+		if numeric && b[i] == ',' {
+			t.pos += i + 1
+			return b[:i]
+		}
+	}
+	t.pos += w
+	return b
+}
+
+// numeric return characters of numeric input field with width w.
+// Leading blanks are ignored. Other blanks are ignored or are zeros
+// by BN or BZ edit descriptor.
+func (t *formatted) numeric(w int) []byte {
+	f := bytes.TrimLeft(t.field(w, true), " ")
+	if t.zero {
+		return bytes.Replace(f, []byte(" "), []byte("0"), -1)
+	}
+	return bytes.Replace(f, []byte(" "), nil, -1)
+}
+
+// mismatch return error of type of datum for edit descriptor
+func (t *formatted) mismatch(expect string, d datum) *ioError {
+	return newIOError(iostatFormat,
+		"Expected %s for item %d in formatted transfer, got %s",
+		expect, t.next, d.typeName())
+}
+
+// output edit datum by data edit descriptor
+func (t *formatted) output(it *formatItem, d datum) {
+	var s string
+	switch it.code {
+	case "I":
+		if !d.isInteger() {
+			panic(t.mismatch("INTEGER", d))
+		}
+		s = editInteger(d.v.Int(), it.w, it.d, t.plus)
+	case "B", "O", "Z":
+		if !d.isInteger() {
+			panic(t.mismatch("INTEGER", d))
+		}
+		s = editBits(d.v.Int(), 8*unformattedSize(d.v, false),
+			map[string]int{"B": 2, "O": 8, "Z": 16}[it.code], it.w, it.d)
+	case "G":
+		switch {
+		case d.isInteger():
+			t.output(&formatItem{code: "I", w: it.w, d: -1}, d)
+			return
+		case d.v.Kind() == reflect.Bool:
+			w := it.w
+			if w == 0 {
+				w = 1
+			}
+			t.output(&formatItem{code: "L", w: w}, d)
+			return
+		case d.typeName() == "CHARACTER":
+			w := it.w
+			if w == 0 {
+				w = -1
+			}
+			t.output(&formatItem{code: "A", w: w}, d)
+			return
+		}
+		if d.typeName() != "REAL" && d.part == 0 {
+			panic(t.mismatch("REAL", d))
+		}
+		if it.w > 0 && it.d < 0 {
+			panic(newIOError(iostatFormat, "Period required in format specifier"))
+		}
+		var ok bool
+		if s, ok = editGeneral(d.float(), it.w, it.d, it.e, t.scale, t.plus); !ok {
+			panic(newIOError(iostatFormat, "Scale factor out of range in format"))
+		}
+	case "F", "E", "D":
+		if d.typeName() != "REAL" && d.part == 0 {
+			panic(t.mismatch("REAL", d))
+		}
+		if it.code == "F" {
+			s = editFixed(d.float()*math.Pow10(t.scale), it.w, it.d, t.plus)
+			break
+		}
+		var ok bool
+		if s, ok = editExponent(d.float(), it.w, it.d, it.e, t.scale,
+			it.code, t.plus); !ok {
+			panic(newIOError(iostatFormat, "Scale factor out of range in format"))
+		}
+	case "A":
+		b, ok := d.character()
+		if !ok {
+			panic(t.mismatch("CHARACTER", d))
+		}
+		switch {
+		case it.w < 0:
+		case it.w < len(b):
+			b = b[:it.w]
+		default:
+			b = append([]byte(strings.Repeat(" ", it.w-len(b))), b...)
+		}
+		s = string(b)
+	case "L":
+		if d.v.Kind() != reflect.Bool {
+			panic(t.mismatch("LOGICAL", d))
+		}
+		s = "F"
+		if d.v.Bool() {
+			s = "T"
+		}
+		s = fit(s, it.w)
+	}
+	t.put([]byte(s))
+}
+
+// fit return right justified value in field with width w. If value is
+// longer field, then field is filled by asterisks. Zero width is
+// minimal width.
+func fit(s string, w int) string {
+	switch {
+	case w <= 0:
+		return s
+	case len(s) > w:
+		return strings.Repeat("*", w)
+	}
+	return strings.Repeat(" ", w-len(s)) + s
+}
+
+// signOf return sign of value. Plus sign is optional and written
+// only for SP edit descriptor.
+func signOf(negative, plus bool) string {
+	switch {
+	case negative:
+		return "-"
+	case plus:
+		return "+"
+	}
+	return ""
+}
+
+// editInteger return integer value by edit descriptor Iw.m
+func editInteger(v int64, w, m int, plus bool) string {
+	var s string
+	if v < 0 {
+		s = strconv.FormatUint(uint64(-v), 10)
+	} else {
+		s = strconv.FormatUint(uint64(v), 10)
+	}
+	if m >= 0 {
+		if v == 0 && m == 0 {
+			s = ""
+		}
+		if len(s) < m {
+			s = strings.Repeat("0", m-len(s)) + s
+		}
+	}
+	return fit(signOf(v < 0, plus)+s, w)
+}
+
+// editBits return integer value with size in bits by edit descriptor
+// Bw.m, Ow.m or Zw.m for base 2, 8 or 16. Negative value is written
+// in two's complement form.
+func editBits(v int64, size, base, w, m int) string {
+	u := uint64(v)
+	if size < 64 {
+		u &= 1<<uint(size) - 1
+	}
+	s := strings.ToUpper(strconv.FormatUint(u, base))
+	if m >= 0 {
+		if u == 0 && m == 0 {
+			s = ""
+		}
+		if len(s) < m {
+			s = strings.Repeat("0", m-len(s)) + s
+		}
+	}
+	return fit(s, w)
+}
+
+// editSpecial return infinity or NaN value in field with width w
+func editSpecial(x float64, w int, plus bool) string {
+	if math.IsNaN(x) {
+		return fit("NaN", w)
+	}
+	s := "Inf"
+	if w == 0 || w >= 8 {
+		s = "Infinity"
+	}
+	if sign := signOf(x < 0, plus); sign != "" {
+		s = sign + s
+		if w == 8 {
+			s = sign + "Inf"
+		}
+	}
+	return fit(s, w)
+}
+
+// optionalZero remove optional zero before decimal point, if value
+// is longer field with width w
+func optionalZero(sign, s string, w int) string {
+	if w > 0 && len(sign)+len(s) > w && strings.HasPrefix(s, "0.") {
+		s = s[1:]
+	}
+	return fit(sign+s, w)
+}
+
+// editFixed return real value by edit descriptor Fw.d
+func editFixed(x float64, w, d int, plus bool) string {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return editSpecial(x, w, plus)
+	}
+	sign := signOf(math.Signbit(x), plus)
+	s := strconv.FormatFloat(math.Abs(x), 'f', d, 64)
+	if d == 0 {
+		s += "."
+	}
+	return optionalZero(sign, s, w)
+}
+
+// editExponent return real value by edit descriptor Ew.dEe or Dw.d
+// with scale factor k. Digits of exponent e is -1, if absent.
+// Result is false for not valid scale factor.
+func editExponent(x float64, w, d, e, k int, letter string, plus bool) (string, bool) {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return editSpecial(x, w, plus), true
+	}
+	if k <= -d || d+2 <= k {
+		return "", false
+	}
+	sign := signOf(math.Signbit(x), plus)
+
+	// significant digits
+	n := d + k
+	if k > 0 {
+		n = d + 1
+	}
+	digits := strings.Repeat("0", n)
+	exp := 0
+	if x != 0 {
+		m := strconv.FormatFloat(math.Abs(x), 'e', n-1, 64)
+		pos := strings.IndexByte(m, 'e')
+		digits = strings.Replace(m[:pos], ".", "", 1)
+		exp, _ = strconv.Atoi(m[pos+1:])
+		exp++
+	}
+	exp -= k
+
+	var s string
+	if k <= 0 {
+		s = "0." + strings.Repeat("0", -k) + digits
+	} else {
+		s = digits[:k] + "." + digits[k:]
+	}
+
+	es := "+"
+	if exp < 0 {
+		es = "-"
+		exp = -exp
+	}
+	switch {
+	case e > 0:
+		digits := strconv.Itoa(exp)
+		if len(digits) > e {
+			return strings.Repeat("*", w), true
+		}
+		s += letter + es + strings.Repeat("0", e-len(digits)) + digits
+	case exp <= 99:
+		s += letter + es + strconv.Itoa(100 + exp)[1:]
+	case exp <= 999:
+		s += es + strconv.Itoa(exp)
+	default:
+		return strings.Repeat("*", w), true
+	}
+	return optionalZero(sign, s, w), true
+}
+
+// editGeneral return real value by edit descriptor Gw.dEe with scale
+// factor k. Value is written by F editing with trailing blanks, if
+// magnitude after rounding to d significant digits is between 0.1
+// and 10**d, otherwise by E editing. Descriptor G0 is written as
+// list-directed value without blanks.
+func editGeneral(x float64, w, d, e, k int, plus bool) (string, bool) {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return editSpecial(x, w, plus), true
+	}
+	if w == 0 {
+		s := strings.TrimSpace(listReal(x, false))
+		if plus && !math.Signbit(x) {
+			s = "+" + s
+		}
+		return s, true
+	}
+	n := 4
+	if e > 0 {
+		n = e + 2
+	}
+	if x == 0 {
+		return editFixed(x, w-n, d-1, plus) + strings.Repeat(" ", n), true
+	}
+	m := strconv.FormatFloat(math.Abs(x), 'e', d-1, 64)
+	exp, _ := strconv.Atoi(m[strings.IndexByte(m, 'e')+1:])
+	if exp++; 0 <= exp && exp <= d {
+		return editFixed(x, w-n, d-exp, plus) + strings.Repeat(" ", n), true
+	}
+	return editExponent(x, w, d, e, k, "E", plus)
+}
+
+// input set datum by data edit descriptor
+func (t *formatted) input(it *formatItem, d datum) {
+	switch it.code {
+	case "I":
+		if !d.isInteger() {
+			panic(t.mismatch("INTEGER", d))
+		}
+		f := t.numeric(it.w)
+		if len(f) == 0 {
+			f = []byte("0")
+		}
+		v, err := strconv.ParseInt(string(f), 10, 64)
+		if err != nil && err.(*strconv.NumError).Err == strconv.ErrSyntax {
+			panic(newIOError(iostatReadValue, "Bad value during integer read"))
+		}
+		if err != nil || d.v.OverflowInt(v) {
+			panic(newIOError(iostatReadOverflow, "Value overflowed during integer read"))
+		}
+		d.v.SetInt(v)
+	case "B", "O", "Z":
+		if !d.isInteger() {
+			panic(t.mismatch("INTEGER", d))
+		}
+		f := t.numeric(it.w)
+		if len(f) == 0 {
+			f = []byte("0")
+		}
+		size := uint(8 * unformattedSize(d.v, false))
+		u, err := strconv.ParseUint(string(f),
+			map[string]int{"B": 2, "O": 8, "Z": 16}[it.code], int(size))
+		if err != nil && err.(*strconv.NumError).Err == strconv.ErrSyntax {
+			panic(newIOError(iostatReadValue, "Bad value during integer read"))
+		}
+		if err != nil {
+			panic(newIOError(iostatReadOverflow, "Value overflowed during integer read"))
+		}
+		// two's complement form
+		v := int64(u)
+		if size < 64 && u >= 1<<(size-1) {
+			v -= 1 << size
+		}
+		d.v.SetInt(v)
+	case "G":
+		switch {
+		case d.isInteger():
+			t.input(&formatItem{code: "I", w: it.w, d: -1}, d)
+		case d.v.Kind() == reflect.Bool:
+			t.input(&formatItem{code: "L", w: it.w}, d)
+		case d.typeName() == "CHARACTER":
+			t.input(&formatItem{code: "A", w: it.w}, d)
+		default:
+			dd := it.d
+			if dd < 0 {
+				dd = 0
+			}
+			t.input(&formatItem{code: "F", w: it.w, d: dd}, d)
+		}
+	case "F", "E", "D":
+		if d.typeName() != "REAL" && d.part == 0 {
+			panic(t.mismatch("REAL", d))
+		}
+		x, ok := readReal(t.numeric(it.w), it.d, t.scale)
+		if !ok {
+			panic(newIOError(iostatReadValue, "Bad value during floating point read"))
+		}
+		d.setFloat(x)
+	case "A":
+		if d.v.Kind() != reflect.Slice {
+			panic(t.mismatch("CHARACTER", d))
+		}
+		b := d.v.Bytes()
+		w := it.w
+		if w < 0 {
+			w = len(b)
+		}
+		f := t.field(w, false)
+		if w >= len(b) {
+			copy(b, f[w-len(b):])
+			break
+		}
+		n := copy(b, f)
+		for ; n < len(b); n++ {
+			b[n] = ' '
+		}
+	case "L":
+		if d.v.Kind() != reflect.Bool {
+			panic(t.mismatch("LOGICAL", d))
+		}
+		f := bytes.TrimLeft(t.field(it.w, true), " ")
+		f = bytes.TrimPrefix(f, []byte("."))
+		if len(f) == 0 {
+			panic(newIOError(iostatReadValue, "Bad logical value"))
+		}
+		switch f[0] {
+		case 'T', 't':
+			d.v.SetBool(true)
+		case 'F', 'f':
+			d.v.SetBool(false)
+		default:
+			panic(newIOError(iostatReadValue, "Bad logical value"))
+		}
+	}
+}
+
+// readReal return value of input field for edit descriptor Fw.d.
+// Blanks are removed before. If field has no decimal point, then last
+// d digits are fraction. Scale factor k is used for field without
+// exponent.
+func readReal(f []byte, d, k int) (float64, bool) {
+	s := strings.ToUpper(strings.Replace(string(f), " ", "", -1))
+	if s == "" {
+		return 0, true
+	}
+	if x, err := strconv.ParseFloat(s, 64); err == nil &&
+		strings.ContainsAny(s, "IN") {
+		// infinity or NaN
+		return x, true
+	}
+
+	var sign string
+	if s[0] == '+' || s[0] == '-' {
+		sign, s = s[:1], s[1:]
+	}
+
+	// mantissa
+	var digits string
+	exp := -d
+	point := false
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '.' && !point {
+			point = true
+			exp = 0
+			continue
+		}
+		if c < '0' || '9' < c {
+			break
+		}
+		digits += string(c)
+		if point {
+			exp--
+		}
+	}
+	if digits == "" {
+		return 0, false
+	}
+
+	// exponent
+	if i < len(s) {
+		e := s[i:]
+		switch e[0] {
+		case 'E', 'D', 'Q':
+			e = e[1:]
+		case '+', '-':
+		default:
+			return 0, false
+		}
+		v, err := strconv.Atoi(e)
+		if err != nil {
+			return 0, false
+		}
+		exp += v
+	} else {
+		exp -= k
+	}
+
+	x, err := strconv.ParseFloat(sign+digits+"e"+strconv.Itoa(exp), 64)
+	if err != nil && err.(*strconv.NumError).Err != strconv.ErrRange {
+		return 0, false
+	}
+	return x, true
+}
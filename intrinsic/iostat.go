@@ -0,0 +1,95 @@
+package intrinsic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// Codes of status of input/output statements are same as in gfortran.
+// Error of operating system has code of errno.
+const (
+	iostatEOR            = -2 // end of record
+	iostatEnd            = -1 // end of file
+	iostatOS             = 5000
+	iostatOptionConflict = 5001
+	iostatBadOption      = 5002
+	iostatMissingOption  = 5003
+	iostatAlreadyOpen    = 5004
+	iostatBadUnit        = 5005
+	iostatFormat         = 5006
+	iostatBadAction      = 5007
+	iostatEndfile        = 5008
+	iostatBadUS          = 5009
+	iostatReadValue      = 5010
+	iostatReadOverflow   = 5011
+	iostatDirectEOR      = 5015
+	iostatShortRecord    = 5016
+	iostatCorruptFile    = 5017
+)
+
+// ioError is error of input/output statement
+type ioError struct {
+	code int
+	msg  string
+}
+
+func (e *ioError) Error() string {
+	return e.msg
+}
+
+func newIOError(code int, format string, a ...interface{}) *ioError {
+	return &ioError{code: code, msg: fmt.Sprintf(format, a...)}
+}
+
+// osError return error of operating system with prefix of message.
+// Code of error is errno.
+func osError(err error, format string, a ...interface{}) *ioError {
+	e := &ioError{code: iostatOS, msg: err.Error()}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		e.code = int(errno)
+		e.msg = errno.Error()
+	}
+	// message is same as strerror in C
+	if e.msg != "" {
+		e.msg = strings.ToUpper(e.msg[:1]) + e.msg[1:]
+	}
+	e.msg = fmt.Sprintf(format, a...) + ": " + e.msg
+	return e
+}
+
+// status store code of statement in variable of specifier IOSTAT,
+// message of error in variable of specifier IOMSG and return the code.
+// If error is not handled by specifiers IOSTAT, ERR, END or EOR, then
+// program is stopped. Specifier ERR handles errors, END handles end
+// of file and EOR handles end of record. Caller holds lock of runtime.
+func (rt *Runtime) status(sp map[string]interface{}, err *ioError) int {
+	code := 0
+	if err != nil {
+		code = err.code
+	}
+	if v, ok := sp["IOSTAT"]; ok {
+		setSpecifier(v, code)
+	}
+	if err == nil {
+		return 0
+	}
+	if v, ok := sp["IOMSG"]; ok {
+		setSpecifier(v, err.msg)
+	}
+	handler := "ERR"
+	switch code {
+	case iostatEnd:
+		handler = "END"
+	case iostatEOR:
+		handler = "EOR"
+	}
+	_, iostat := sp["IOSTAT"]
+	_, label := sp[handler]
+	if !iostat && !label {
+		rt.runtimeError("%s", err.msg)
+	}
+	return code
+}
@@ -0,0 +1,489 @@
+package intrinsic
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// elements return addressable values of scalar or array in array element
+// order of Fortran. First subscript is changing fastest.
+// Value of type []byte is scalar of type CHARACTER.
+func elements(value interface{}) (es []reflect.Value) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !isArray(v) {
+		return []reflect.Value{v}
+	}
+
+	// extents of array
+	var extents []int
+	for e := v; isArray(e); {
+		extents = append(extents, e.Len())
+		if e.Len() == 0 {
+			return nil
+		}
+		e = e.Index(0)
+	}
+
+	index := make([]int, len(extents))
+	for {
+		e := v
+		for _, i := range index {
+			e = e.Index(i)
+		}
+		es = append(es, e)
+
+		// next element
+		d := 0
+		for ; d < len(index); d++ {
+			index[d]++
+			if index[d] < extents[d] {
+				break
+			}
+			index[d] = 0
+		}
+		if d == len(index) {
+			return
+		}
+	}
+}
+
+func isArray(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+}
+
+// listInteger return integer value in list-directed format.
+// Width of field depends on kind of integer as in gfortran.
+func listInteger(v int64, kind reflect.Kind) string {
+	w := 11
+	switch kind {
+	case reflect.Int8:
+		w = 4
+	case reflect.Int16:
+		w = 6
+	case reflect.Int64:
+		w = 20
+	}
+	return fmt.Sprintf("%*d", w, v)
+}
+
+// listReal return real value in list-directed format.
+// Format is same as 1PG25.17E3 of gfortran for REAL*8 and
+// 1PG16.9E2 for REAL*4.
+func listReal(v float64, single bool) string {
+	w, d, e := 25, 17, 3
+	if single {
+		w, d, e = 16, 9, 2
+		v = float64(float32(v))
+	}
+	switch {
+	case math.IsNaN(v):
+		return fmt.Sprintf("%*s", w, "NaN")
+	case math.IsInf(v, 1):
+		return fmt.Sprintf("%*s", w, "Infinity")
+	case math.IsInf(v, -1):
+		return fmt.Sprintf("%*s", w, "-Infinity")
+	case v == 0:
+		s := "0." + strings.Repeat("0", d-1)
+		if math.Signbit(v) {
+			s = "-" + s
+		}
+		return fmt.Sprintf("%*s%s", w-e-2, s, strings.Repeat(" ", e+2))
+	}
+
+	// exponent after rounding to d significant digits
+	m := strconv.FormatFloat(v, 'e', d-1, 64)
+	pos := strings.IndexByte(m, 'e')
+	exp, _ := strconv.Atoi(m[pos+1:])
+	n := exp + 1
+
+	if 0 <= n && n <= d {
+		s := strconv.FormatFloat(v, 'f', d-n, 64)
+		return fmt.Sprintf("%*s%s", w-e-2, s, strings.Repeat(" ", e+2))
+	}
+
+	sign := "+"
+	if exp < 0 {
+		sign = "-"
+		exp = -exp
+	}
+	return fmt.Sprintf("%*s", w, fmt.Sprintf("%sE%s%0*d", m[:pos], sign, e, exp))
+}
+
+// listValue return value in list-directed format.
+// If delim is true, then character value is delimited by quotes.
+// If single is true, then REAL or COMPLEX value has single precision.
+func listValue(v reflect.Value, delim, single bool) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return listInteger(v.Int(), v.Kind())
+	case reflect.Float32, reflect.Float64:
+		return listReal(v.Float(), single || v.Kind() == reflect.Float32)
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		single = single || v.Kind() == reflect.Complex64
+		return fmt.Sprintf("(%s,%s)",
+			listReal(real(c), single), listReal(imag(c), single))
+	case reflect.Bool:
+		if v.Bool() {
+			return "T"
+		}
+		return "F"
+	case reflect.Uint8:
+		return character([]byte{byte(v.Uint())}, delim)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return character(v.Bytes(), delim)
+		}
+	case reflect.String:
+		return character([]byte(v.String()), delim)
+	}
+	panic(fmt.Errorf("not valid type for list-directed output: %v", v.Type()))
+}
+
+func character(b []byte, delim bool) string {
+	if !delim {
+		return string(b)
+	}
+	return "\"" + strings.Replace(string(b), "\"", "\"\"", -1) + "\""
+}
+
+// ListCompression enables repeated-value compression of list-directed
+// output of runtime Default. Consecutive equal values are written as
+// r*c like in NAMELIST output. By default values are written as in
+// gfortran without compression. Other runtimes use
+// Runtime.SetListCompression.
+var ListCompression bool
+
+// listItem is value of list-directed output
+type listItem struct {
+	text      string
+	character bool
+}
+
+// listItems return values of list-directed output with repeated-value
+// compression, if compress is true
+func listItems(a []interface{}, compress bool) (items []listItem) {
+	for _, item := range a {
+		s, isSingle := item.(single)
+		if isSingle {
+			item = s.value
+		}
+		for _, e := range elements(item) {
+			_, ok := datum{v: e}.character()
+			items = append(items, listItem{
+				text:      listValue(e, false, isSingle),
+				character: ok,
+			})
+		}
+	}
+	if !compress {
+		return
+	}
+	var compressed []listItem
+	for i := 0; i < len(items); {
+		r := 1
+		for ; i+r < len(items) && !items[i].character; r++ {
+			if items[i+r] != items[i] {
+				break
+			}
+		}
+		item := items[i]
+		if r > 1 {
+			item.text = fmt.Sprintf("%d*%s", r, strings.TrimLeft(item.text, " "))
+		}
+		compressed = append(compressed, item)
+		i += r
+	}
+	return compressed
+}
+
+// writeList is list-directed output in records with length recl.
+// Each record is started by blank. Values are separated by blank, but
+// character values are written without separator between them.
+// Value, which is not fit in record, is written in next record.
+//
+// Fortran:
+//  WRITE (*, *) 'N =', N, X
+// Output:
+//  N =          42   3.1415926535897931
+func writeList(w io.Writer, recl int, a []interface{}, compress bool) error {
+	var buf bytes.Buffer
+	var record []byte
+	items := listItems(a, compress)
+	if len(items) > 0 {
+		record = append(record, ' ')
+	}
+	for i, item := range items {
+		if i > 0 && !(item.character && items[i-1].character) {
+			record = append(record, ' ')
+		}
+		if len(record)+len(item.text) > recl && len(record) > 1 {
+			// value in next record
+			buf.Write(bytes.TrimRight(record, " "))
+			buf.WriteByte('\n')
+			record = append(record[:0], ' ')
+		}
+		record = append(record, item.text...)
+	}
+	buf.Write(record)
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// listInput is state of list-directed input. End of record is
+// returned as newline and is same as blank.
+type listInput struct {
+	r      *bufio.Reader
+	record []byte
+	pos    int
+}
+
+// peek return next character of input. Next record is read only
+// after end of current record.
+func (l *listInput) peek() (byte, *ioError) {
+	if l.pos > len(l.record) {
+		line, e := nextLine(l.r)
+		if e != nil {
+			return 0, e
+		}
+		l.record, l.pos = line, 0
+	}
+	if l.pos == len(l.record) {
+		return '\n', nil
+	}
+	return l.record[l.pos], nil
+}
+
+// isListSeparator return true for blank, comma, slash or end of record
+func isListSeparator(c byte) bool {
+	return c == ' ' || c == '\t' || c == ',' || c == '/' || c == '\n'
+}
+
+// inputValue is value of list-directed input
+type inputValue struct {
+	count  int    // repeat count
+	text   string // text of value
+	quoted bool   // character value between quotes
+	null   bool   // null value
+}
+
+// value return next value of list-directed input.
+// Result end is true for slash, which terminates input.
+//
+// Examples of values:
+//  12
+//  1.5D-3
+//  3*1.5
+//  2*'abc'
+//  (1.0, 2.0)
+//  4*
+// Examples of null values:
+//  , ,
+//  1,,2
+func (l *listInput) value() (v inputValue, end bool, e *ioError) {
+	v.count = 1
+	c, e := l.peek()
+	for ; e == nil && (c == ' ' || c == '\t' || c == '\n'); c, e = l.peek() {
+		l.pos++
+	}
+	switch {
+	case e != nil:
+		return v, false, e
+	case c == '/':
+		return v, true, nil
+	case c == ',':
+		l.pos++
+		v.null = true
+		return v, false, nil
+	}
+
+	// repeat count
+	start := l.pos
+	for ; l.pos < len(l.record) && '0' <= l.record[l.pos] && l.record[l.pos] <= '9'; l.pos++ {
+	}
+	if l.pos > start && l.pos < len(l.record) && l.record[l.pos] == '*' {
+		n, err := strconv.Atoi(string(l.record[start:l.pos]))
+		if err != nil || n == 0 {
+			return v, false, newIOError(iostatReadValue,
+				"Zero repeat count in list input")
+		}
+		v.count = n
+		l.pos++
+		if l.pos == len(l.record) || isListSeparator(l.record[l.pos]) {
+			v.null = true
+			l.separator()
+			return v, false, nil
+		}
+	} else {
+		l.pos = start
+	}
+
+	c = l.record[l.pos]
+	switch c {
+	case '\'', '"':
+		// character value may be continued in next record
+		var s []byte
+		for l.pos++; ; l.pos++ {
+			ch, e := l.peek()
+			if e != nil {
+				return v, false, e
+			}
+			switch {
+			case ch == '\n':
+				continue
+			case ch != c:
+				s = append(s, ch)
+				continue
+			}
+			if l.pos+1 < len(l.record) && l.record[l.pos+1] == c {
+				s = append(s, c)
+				l.pos++
+				continue
+			}
+			break
+		}
+		l.pos++
+		v.text, v.quoted = string(s), true
+
+	case '(':
+		// complex value may be continued in next record
+		var s []byte
+		for ; ; l.pos++ {
+			ch, e := l.peek()
+			if e != nil {
+				return v, false, e
+			}
+			if ch != '\n' {
+				s = append(s, ch)
+			}
+			if ch == ')' {
+				break
+			}
+		}
+		l.pos++
+		v.text = string(s)
+
+	default:
+		start := l.pos
+		for ; l.pos < len(l.record) && !isListSeparator(l.record[l.pos]); l.pos++ {
+		}
+		v.text = string(l.record[start:l.pos])
+	}
+	l.separator()
+	return v, false, nil
+}
+
+// separator skip blanks and one comma after value in current record
+func (l *listInput) separator() {
+	for l.pos < len(l.record) && (l.record[l.pos] == ' ' || l.record[l.pos] == '\t') {
+		l.pos++
+	}
+	if l.pos < len(l.record) && l.record[l.pos] == ',' {
+		l.pos++
+	}
+}
+
+// setListValue store value of list-directed input in variable.
+// Item is number of element in input list.
+func setListValue(e reflect.Value, v inputValue, item int) *ioError {
+	bad := func(kind string) *ioError {
+		return newIOError(iostatReadValue,
+			"Bad %s for item %d in list input", kind, item)
+	}
+	if v.quoted {
+		if _, ok := (datum{v: e}).character(); !ok {
+			return bad((datum{v: e}).typeName())
+		}
+	}
+	text := strings.TrimSpace(v.text)
+	switch e.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimPrefix(text, "+"), 10, 64)
+		if err != nil {
+			return bad("integer")
+		}
+		if e.OverflowInt(i) {
+			return newIOError(iostatReadOverflow,
+				"Integer overflow while reading item %d", item)
+		}
+		e.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseReal(text)
+		if err != nil {
+			return bad("real number")
+		}
+		e.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(text, "("), ")"), ",")
+		if !strings.HasPrefix(text, "(") || !strings.HasSuffix(text, ")") || len(parts) != 2 {
+			return bad("complex value")
+		}
+		re, err1 := parseReal(parts[0])
+		im, err2 := parseReal(parts[1])
+		if err1 != nil || err2 != nil {
+			return bad("complex value")
+		}
+		e.SetComplex(complex(re, im))
+	case reflect.Bool:
+		t := strings.ToUpper(strings.TrimPrefix(text, "."))
+		switch {
+		case strings.HasPrefix(t, "T"):
+			e.SetBool(true)
+		case strings.HasPrefix(t, "F"):
+			e.SetBool(false)
+		default:
+			return bad("logical value")
+		}
+	default:
+		if err := setValue(e, &lexem{text: v.text}); err != nil {
+			return bad((datum{v: e}).typeName())
+		}
+	}
+	return nil
+}
+
+// readList is list-directed input. Values are separated by blanks,
+// comma or end of record. Null value does not change variable.
+// Slash terminates input and rest of variables are not changed.
+//
+// Input:
+//  3*1.5, , 'it''s' /
+func readList(r *bufio.Reader, a []interface{}) *ioError {
+	line, e := nextLine(r)
+	if e != nil {
+		return e
+	}
+	l := listInput{r: r, record: line}
+
+	var es []reflect.Value
+	for _, item := range a {
+		es = append(es, elements(item)...)
+	}
+	var v inputValue
+	for i := 0; i < len(es); i++ {
+		if v.count == 0 {
+			var end bool
+			if v, end, e = l.value(); e != nil || end {
+				return e
+			}
+		}
+		v.count--
+		if v.null {
+			continue
+		}
+		if e := setListValue(es[i], v, i+1); e != nil {
+			return e
+		}
+	}
+	return nil
+}
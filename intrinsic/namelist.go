@@ -0,0 +1,531 @@
+package intrinsic
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// namelist is group of variables for NAMELIST input and output
+type namelist struct {
+	name  string // name of group in upper case
+	items []namelistItem
+}
+
+// namelistItem is name and address of variable in NAMELIST group
+type namelistItem struct {
+	name  string
+	value interface{} // pointer to variable or pointer to array
+}
+
+// newNamelist return NAMELIST group from pairs of name and
+// pointer to variable.
+func newNamelist(group string, items []interface{}) (nml namelist) {
+	nml.name = strings.ToUpper(group)
+	if len(items)%2 != 0 {
+		panic(fmt.Errorf("not valid items of NAMELIST %s", nml.name))
+	}
+	for i := 0; i < len(items); i += 2 {
+		nml.items = append(nml.items, namelistItem{
+			name:  strings.ToUpper(string(castToBytes(items[i]))),
+			value: items[i+1],
+		})
+	}
+	return
+}
+
+// WRITENML writes NAMELIST group to unit.
+// Items are pairs of name and pointer to variable in order of declaration.
+//
+// Fortran:
+//  NAMELIST /PARAMS/ DT, NSTEPS
+//  WRITE (6, NML=PARAMS)
+// Go code:
+//  intrinsic.WRITENML(6, "PARAMS", "DT", DT, "NSTEPS", NSTEPS)
+// Output:
+//  &PARAMS
+//   DT=  0.10000000000000001     ,
+//   NSTEPS=        100,
+//   /
+func (rt *Runtime) WRITENML(unit interface{}, group string, items ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	unit, sp := controlList(unit)
+	nml := newNamelist(group, items)
+	w, done, e := rt.output(unit, sp)
+	if e != nil {
+		return rt.status(sp, e)
+	}
+	if err := writeNamelist(w, nml); err != nil {
+		e = osError(err, "Cannot write NAMELIST %s", nml.name)
+	}
+	if err := done(); e == nil {
+		e = err
+	}
+	return rt.status(sp, e)
+}
+
+func writeNamelist(w io.Writer, nml namelist) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "&%s\n", nml.name)
+	for _, item := range nml.items {
+		fmt.Fprintf(&buf, " %s=", item.name)
+		es := elements(item.value)
+		for i := 0; i < len(es); {
+			value := listValue(es[i], true, false)
+
+			// repeat count of equal values
+			r := 1
+			for ; i+r < len(es); r++ {
+				if listValue(es[i+r], true, false) != value {
+					break
+				}
+			}
+			if r > 1 {
+				value = fmt.Sprintf("%d*%s", r, strings.TrimLeft(value, " "))
+			}
+			fmt.Fprintf(&buf, "%s,", value)
+			i += r
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(" /\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// READNML reads NAMELIST group from unit.
+// Items are pairs of name and pointer to variable.
+// Names of variables are case-insensitive.
+//
+// Input:
+//  &PARAMS dt=0.1, nsteps=100
+//   ARR = 3*1.5, 2.0 ! comment
+//  /
+func (rt *Runtime) READNML(unit interface{}, group string, items ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	unit, sp := controlList(unit)
+	nml := newNamelist(group, items)
+	r, e := rt.input(unit, sp)
+	if e != nil {
+		return rt.status(sp, e)
+	}
+	err := readNamelist(r, nml)
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		e = newIOError(iostatEnd, "End of file")
+	default:
+		e = newIOError(iostatReadValue, "Cannot read NAMELIST %s: %v", nml.name, err)
+	}
+	return rt.status(sp, e)
+}
+
+func readNamelist(r io.Reader, nml namelist) error {
+	body, err := namelistBody(r, nml.name)
+	if err != nil {
+		return err
+	}
+	ls, err := namelistLexems(body)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(ls); {
+		// name of variable
+		if ls[i].kind != nlWord {
+			return fmt.Errorf("name of variable is expected: %q", ls[i].text)
+		}
+		name := strings.ToUpper(ls[i].text)
+		i++
+		var subscripts string
+		if i < len(ls) && ls[i].kind == nlParen && ls[i].attached {
+			subscripts = ls[i].text
+			i++
+		}
+		if i >= len(ls) || ls[i].kind != nlEqual {
+			return fmt.Errorf("symbol '=' is expected after %s", name)
+		}
+		i++
+
+		var item *namelistItem
+		for k := range nml.items {
+			if nml.items[k].name == name {
+				item = &nml.items[k]
+				break
+			}
+		}
+		if item == nil {
+			return fmt.Errorf("variable %s is not in group", name)
+		}
+		es := elements(item.value)
+		if subscripts != "" {
+			if es, err = namelistSection(item.value, subscripts); err != nil {
+				return fmt.Errorf("variable %s: %v", name, err)
+			}
+		}
+
+		// values of variable up to next name
+		var values []*lexem
+		expectValue := true
+		for ; i < len(ls) && !isNamelistName(ls, i); i++ {
+			if ls[i].kind == nlComma {
+				if expectValue {
+					// null value
+					values = append(values, nil)
+				}
+				expectValue = true
+				continue
+			}
+			count, value, err := repeatValue(ls, &i)
+			if err != nil {
+				return fmt.Errorf("variable %s: %v", name, err)
+			}
+			for k := 0; k < count; k++ {
+				values = append(values, value)
+			}
+			expectValue = false
+		}
+
+		if len(values) > len(es) {
+			return fmt.Errorf("too many values for variable %s", name)
+		}
+		for k, value := range values {
+			if value == nil {
+				continue
+			}
+			if err := setValue(es[k], value); err != nil {
+				return fmt.Errorf("variable %s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// namelistBody return text of group between name of group and
+// symbol '/'. Other groups are skipped.
+func namelistBody(r io.Reader, group string) (body []byte, err error) {
+	group = strings.ToUpper(group)
+	var b [1]byte
+	read := func() (byte, error) {
+		_, err := io.ReadFull(r, b[:])
+		return b[0], err
+	}
+	for {
+		// find start of group
+		var c byte
+		for c != '&' && c != '$' {
+			if c, err = read(); err != nil {
+				return nil, fmt.Errorf("group is not found: %w", err)
+			}
+		}
+		var name []byte
+		for {
+			if c, err = read(); err != nil {
+				return nil, err
+			}
+			if unicode.IsSpace(rune(c)) {
+				break
+			}
+			name = append(name, byte(unicode.ToUpper(rune(c))))
+		}
+
+		// text up to end of group
+		body = body[:0]
+		var quote byte
+		var comment bool
+		for {
+			if c, err = read(); err != nil {
+				return nil, fmt.Errorf("end of group is not found: %v", err)
+			}
+			if comment {
+				comment = c != '\n'
+				if !comment {
+					body = append(body, c)
+				}
+				continue
+			}
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				body = append(body, c)
+				continue
+			}
+			if c == '\'' || c == '"' {
+				quote = c
+			}
+			if c == '!' {
+				comment = true
+				continue
+			}
+			if c == '/' {
+				break
+			}
+			body = append(body, c)
+			if end := bytes.ToUpper(body); bytes.HasSuffix(end, []byte("&END")) ||
+				bytes.HasSuffix(end, []byte("$END")) {
+				body = body[:len(body)-4]
+				break
+			}
+		}
+		if string(name) == group {
+			return body, nil
+		}
+	}
+}
+
+const (
+	nlWord = iota
+	nlString
+	nlParen
+	nlEqual
+	nlComma
+)
+
+// lexem is part of NAMELIST input
+type lexem struct {
+	kind     int
+	text     string
+	attached bool // without blanks before
+}
+
+func namelistLexems(body []byte) (ls []lexem, err error) {
+	attached := false
+	for i := 0; i < len(body); {
+		c := body[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			attached = false
+			i++
+			continue
+		case c == ',' || c == ';':
+			ls = append(ls, lexem{kind: nlComma, text: ","})
+		case c == '=':
+			ls = append(ls, lexem{kind: nlEqual, text: "="})
+		case c == '\'' || c == '"':
+			var s []byte
+			j := i + 1
+			for ; j < len(body); j++ {
+				if body[j] == c {
+					if j+1 < len(body) && body[j+1] == c {
+						s = append(s, c)
+						j++
+						continue
+					}
+					break
+				}
+				s = append(s, body[j])
+			}
+			if j >= len(body) {
+				return nil, fmt.Errorf("not closed string: %s", body[i:])
+			}
+			ls = append(ls, lexem{kind: nlString, text: string(s), attached: attached})
+			i = j + 1
+			attached = true
+			continue
+		case c == '(':
+			j := bytes.IndexByte(body[i:], ')')
+			if j < 0 {
+				return nil, fmt.Errorf("not closed parenthesis: %s", body[i:])
+			}
+			ls = append(ls, lexem{kind: nlParen, text: string(body[i+1 : i+j]), attached: attached})
+			i += j + 1
+			attached = true
+			continue
+		default:
+			j := i
+			for ; j < len(body); j++ {
+				if unicode.IsSpace(rune(body[j])) || bytes.IndexByte([]byte(",;=('\""), body[j]) >= 0 {
+					break
+				}
+			}
+			ls = append(ls, lexem{kind: nlWord, text: string(body[i:j]), attached: attached})
+			i = j
+			attached = true
+			continue
+		}
+		attached = false
+		i++
+	}
+	return
+}
+
+// isNamelistName return true, if lexem is name of variable
+// with optional subscripts before symbol '='.
+func isNamelistName(ls []lexem, i int) bool {
+	if ls[i].kind != nlWord {
+		return false
+	}
+	if i+1 < len(ls) && ls[i+1].kind == nlParen && ls[i+1].attached {
+		i++
+	}
+	return i+1 < len(ls) && ls[i+1].kind == nlEqual
+}
+
+// repeatValue return value with repeat count.
+// Examples:
+//  1.5
+//  3*1.5
+//  2*'abc'
+//  4*
+func repeatValue(ls []lexem, i *int) (count int, value *lexem, err error) {
+	l := ls[*i]
+	count = 1
+	if l.kind == nlWord {
+		if pos := strings.IndexByte(l.text, '*'); pos > 0 {
+			if count, err = strconv.Atoi(l.text[:pos]); err != nil || count < 1 {
+				return 0, nil, fmt.Errorf("not valid repeat count: %s", l.text)
+			}
+			if pos+1 < len(l.text) {
+				l.text = l.text[pos+1:]
+				return count, &l, nil
+			}
+			if *i+1 < len(ls) && ls[*i+1].attached &&
+				(ls[*i+1].kind == nlString || ls[*i+1].kind == nlParen) {
+				*i++
+				return count, &ls[*i], nil
+			}
+			// null values
+			return count, nil, nil
+		}
+	}
+	return count, &ls[*i], nil
+}
+
+// namelistSection return elements of array by subscripts.
+// Examples:
+//  (2)
+//  (1,3)
+//  (2:4)
+func namelistSection(value interface{}, subscripts string) (es []reflect.Value, err error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	all := elements(value)
+
+	// extents of array
+	var extents []int
+	for e := v; isArray(e) && e.Len() > 0; e = e.Index(0) {
+		extents = append(extents, e.Len())
+	}
+	parts := strings.Split(subscripts, ",")
+	if len(parts) != len(extents) {
+		return nil, fmt.Errorf("not valid subscripts: (%s)", subscripts)
+	}
+
+	// position of element in array element order
+	var pos, last, size int
+	size = 1
+	for d, part := range parts {
+		bounds := strings.Split(part, ":")
+		lower, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("not valid subscripts: (%s)", subscripts)
+		}
+		upper := lower
+		if len(bounds) == 2 {
+			if len(parts) != 1 {
+				return nil, fmt.Errorf("section is supported only for one dimension: (%s)", subscripts)
+			}
+			if upper, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("not valid subscripts: (%s)", subscripts)
+			}
+		}
+		if lower < 1 || extents[d] < upper || upper < lower {
+			return nil, fmt.Errorf("subscripts out of bounds: (%s)", subscripts)
+		}
+		pos += (lower - 1) * size
+		last += (upper - 1) * size
+		size *= extents[d]
+	}
+	return all[pos : last+1], nil
+}
+
+// setValue store value of lexem in variable
+func setValue(v reflect.Value, l *lexem) error {
+	text := strings.TrimSpace(l.text)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimPrefix(text, "+"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("not valid integer: %s", text)
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseReal(text)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		parts := strings.Split(text, ",")
+		if l.kind != nlParen || len(parts) != 2 {
+			return fmt.Errorf("not valid complex: %s", text)
+		}
+		re, err := parseReal(parts[0])
+		if err != nil {
+			return err
+		}
+		im, err := parseReal(parts[1])
+		if err != nil {
+			return err
+		}
+		v.SetComplex(complex(re, im))
+	case reflect.Bool:
+		t := strings.ToUpper(strings.TrimPrefix(text, "."))
+		switch {
+		case strings.HasPrefix(t, "T"):
+			v.SetBool(true)
+		case strings.HasPrefix(t, "F"):
+			v.SetBool(false)
+		default:
+			return fmt.Errorf("not valid logical: %s", text)
+		}
+	case reflect.Uint8:
+		c := byte(' ')
+		if len(l.text) > 0 {
+			c = l.text[0]
+		}
+		v.SetUint(uint64(c))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("not valid type: %v", v.Type())
+		}
+		// blank filled character value
+		b := v.Bytes()
+		n := copy(b, l.text)
+		for ; n < len(b); n++ {
+			b[n] = ' '
+		}
+	default:
+		return fmt.Errorf("not valid type: %v", v.Type())
+	}
+	return nil
+}
+
+// parseReal return value of real constant.
+// Examples:
+//  1.5
+//  1.5D-3
+//  .5e2
+func parseReal(text string) (float64, error) {
+	s := strings.Map(func(r rune) rune {
+		if r == 'd' || r == 'D' {
+			return 'E'
+		}
+		return r
+	}, strings.TrimSpace(text))
+	f, err := strconv.ParseFloat(strings.TrimPrefix(s, "+"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("not valid real: %s", text)
+	}
+	return f, nil
+}
@@ -0,0 +1,17 @@
+package intrinsic
+
+import "reflect"
+
+// PRESENT return true, if optional argument is present.
+// Absent optional argument is nil pointer.
+func PRESENT(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Func, reflect.Interface, reflect.Map:
+		return !v.IsNil()
+	}
+	return true
+}
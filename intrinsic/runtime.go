@@ -0,0 +1,202 @@
+package intrinsic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Runtime is runtime of input/output with own table of connected units.
+// Each statement of input/output is executed under lock of runtime,
+// so runtime is safe for concurrent use. Programs with different
+// runtimes do not interfere.
+//
+// Package functions of input/output statements use runtime Default.
+// Generated code uses other runtime by flag of translator:
+//  f4go -io RT program.f
+// Go code:
+//  var RT = intrinsic.NewRuntime()
+//  ...
+//  RT.WRITE(6, []byte("*"), (*N))
+// Runtime is chosen at translation time, so it is one runtime per
+// translated package. Goroutines calling routines of one package
+// share the runtime.
+type Runtime struct {
+	mu          sync.Mutex
+	units       map[int]*unitFile // connected external units
+	compression bool              // compression of list-directed output
+}
+
+// Star is unit `*` of input/output statements. Unit `*` is unit 5
+// for input and unit 6 for output.
+const Star = -1
+
+// NewRuntime return runtime with preconnected units 5 for standard
+// input, 6 for standard output and 0 for standard error output.
+func NewRuntime() *Runtime {
+	rt := &Runtime{units: map[int]*unitFile{}}
+	rt.attach(5, os.Stdin, nil)
+	rt.attach(6, nil, os.Stdout)
+	rt.attach(0, nil, os.Stderr)
+	return rt
+}
+
+// AttachReader connect unit to reader for input. Previous connection
+// of unit is closed. Attached reader is sequential formatted file
+// without positioning, but file of operating system is attached with
+// positioning. Input of unit Star is unit 5.
+//
+// Example:
+//  rt := intrinsic.NewRuntime()
+//  rt.AttachReader(intrinsic.Star, strings.NewReader("1 2 3"))
+func (rt *Runtime) AttachReader(unit int, r io.Reader) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if unit == Star {
+		unit = 5
+	}
+	if unit < 0 {
+		return fmt.Errorf("bad unit number %d", unit)
+	}
+	rt.attach(unit, r, nil)
+	return nil
+}
+
+// AttachWriter connect unit to writer for output. Previous connection
+// of unit is closed. Output of unit Star is unit 6.
+// See AttachReader.
+//
+// Example:
+//  var out bytes.Buffer
+//  rt := intrinsic.NewRuntime()
+//  rt.AttachWriter(intrinsic.Star, &out)
+func (rt *Runtime) AttachWriter(unit int, w io.Writer) error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if unit == Star {
+		unit = 6
+	}
+	if unit < 0 {
+		return fmt.Errorf("bad unit number %d", unit)
+	}
+	rt.attach(unit, nil, w)
+	return nil
+}
+
+// SetListCompression enables or disables repeated-value compression
+// of list-directed output. See ListCompression.
+//
+// Example:
+//  rt := intrinsic.NewRuntime()
+//  rt.SetListCompression(true)
+//  rt.WRITE(6, []byte("*"), []int{1, 1, 1}) // output:  3*1
+func (rt *Runtime) SetListCompression(on bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.compression = on
+}
+
+// listCompression return true for compression of list-directed output.
+// Package variable ListCompression is used only by runtime Default.
+func (rt *Runtime) listCompression() bool {
+	return rt.compression || (rt == Default && ListCompression)
+}
+
+// attach connect unit to reader for input or writer for output
+func (rt *Runtime) attach(unit int, r io.Reader, w io.Writer) {
+	if _, ok := rt.units[unit]; ok {
+		_ = rt.closeUnit(unit, "")
+	}
+	u := &unitFile{action: "READ",
+		access: "SEQUENTIAL", form: "FORMATTED", recl: defaultRecl}
+	var stream interface{} = r
+	if w != nil {
+		u.action, stream = "WRITE", w
+	}
+	if f, ok := stream.(unitStream); ok {
+		// file of operating system
+		u.file = f
+	} else {
+		u.file = attachedStream{r: r, w: w}
+	}
+	rt.units[unit] = u
+}
+
+// Default is runtime of package functions of input/output statements
+var Default = NewRuntime()
+
+// OPEN is statement OPEN of default runtime
+func OPEN(a ...interface{}) int {
+	return Default.OPEN(a...)
+}
+
+// CLOSE is statement CLOSE of default runtime
+func CLOSE(a ...interface{}) int {
+	return Default.CLOSE(a...)
+}
+
+// INQUIRE is statement INQUIRE of default runtime
+func INQUIRE(a ...interface{}) int {
+	return Default.INQUIRE(a...)
+}
+
+// REWIND is statement REWIND of default runtime
+func REWIND(a ...interface{}) int {
+	return Default.REWIND(a...)
+}
+
+// BACKSPACE is statement BACKSPACE of default runtime
+func BACKSPACE(a ...interface{}) int {
+	return Default.BACKSPACE(a...)
+}
+
+// ENDFILE is statement ENDFILE of default runtime
+func ENDFILE(a ...interface{}) int {
+	return Default.ENDFILE(a...)
+}
+
+// WRITE is statement WRITE of default runtime
+func WRITE(unit interface{}, format interface{}, a ...interface{}) int {
+	return Default.WRITE(unit, format, a...)
+}
+
+// READ is statement READ of default runtime
+func READ(unit interface{}, format interface{}, a ...interface{}) int {
+	return Default.READ(unit, format, a...)
+}
+
+// WRITENML is statement WRITE of NAMELIST group of default runtime
+func WRITENML(unit interface{}, group string, items ...interface{}) int {
+	return Default.WRITENML(unit, group, items...)
+}
+
+// READNML is statement READ of NAMELIST group of default runtime
+func READNML(unit interface{}, group string, items ...interface{}) int {
+	return Default.READNML(unit, group, items...)
+}
+
+// STOP is statement STOP of default runtime
+func STOP(code ...interface{}) {
+	Default.STOP(code...)
+}
+
+// ERROR_STOP is statement ERROR STOP of default runtime
+func ERROR_STOP(code ...interface{}) {
+	Default.ERROR_STOP(code...)
+}
+
+// PAUSE is statement PAUSE of default runtime
+func PAUSE(code ...interface{}) {
+	Default.PAUSE(code...)
+}
+
+// AttachReader connect unit of default runtime to reader
+func AttachReader(unit int, r io.Reader) error {
+	return Default.AttachReader(unit, r)
+}
+
+// AttachWriter connect unit of default runtime to writer
+func AttachWriter(unit int, w io.Writer) error {
+	return Default.AttachWriter(unit, w)
+}
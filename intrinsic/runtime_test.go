@@ -0,0 +1,139 @@
+package intrinsic
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRuntimeAttach(t *testing.T) {
+	tcs := []struct {
+		name      string
+		in, out   int // units of statements READ and WRITE
+		rin, rout int // units for attach of reader and writer
+	}{
+		{"star", Star, Star, Star, Star},
+		{"preconnected", 5, 6, Star, Star},
+		{"star of preconnected", Star, Star, 5, 6},
+		{"other", 10, 11, 10, 11},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := NewRuntime()
+			var out bytes.Buffer
+			if err := rt.AttachReader(tc.rin, strings.NewReader("1 2 3\n4\n")); err != nil {
+				t.Fatal(err)
+			}
+			if err := rt.AttachWriter(tc.rout, &out); err != nil {
+				t.Fatal(err)
+			}
+			var a, b, c, d int
+			if st := rt.READ(tc.in, []byte("*"), &a, &b, &c); st != 0 {
+				t.Fatalf("Not valid iostat of READ: %d", st)
+			}
+			if st := rt.READ(tc.in, []byte("(I1)"), &d); st != 0 {
+				t.Fatalf("Not valid iostat of READ: %d", st)
+			}
+			if st := rt.WRITE(tc.out, []byte("(4I2)"), a, b, c, d); st != 0 {
+				t.Fatalf("Not valid iostat of WRITE: %d", st)
+			}
+			if st := rt.WRITE(tc.out, []byte("*"), "end"); st != 0 {
+				t.Fatalf("Not valid iostat of WRITE: %d", st)
+			}
+			if o := out.String(); o != " 1 2 3 4\n end\n" {
+				t.Errorf("Not valid output: %q", o)
+			}
+			var st int
+			rt.READ(CONTROL("UNIT", tc.in, "IOSTAT", &st), []byte("*"), &a)
+			if st != iostatEnd {
+				t.Errorf("Not valid iostat at end of input: %d", st)
+			}
+		})
+	}
+}
+
+func TestRuntimeBadUnit(t *testing.T) {
+	rt := NewRuntime()
+	if err := rt.AttachWriter(-2, &bytes.Buffer{}); err == nil {
+		t.Errorf("Attach of negative unit")
+	}
+	var st int
+	rt.REWIND("UNIT", Star, "IOSTAT", &st)
+	if st != iostatBadUnit {
+		t.Errorf("Not valid iostat of REWIND for unit Star: %d", st)
+	}
+	rt.WRITE(CONTROL("UNIT", 42, "IOSTAT", &st), []byte("*"), 1)
+	if st != iostatBadUnit {
+		t.Errorf("Not valid iostat of WRITE for not connected unit: %d", st)
+	}
+}
+
+func TestRuntimeConcurrent(t *testing.T) {
+	const size = 8
+	var wg sync.WaitGroup
+	outs := make([]bytes.Buffer, size)
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rt := NewRuntime()
+			_ = rt.AttachReader(Star, strings.NewReader(fmt.Sprintf("%d\n", i)))
+			_ = rt.AttachWriter(Star, &outs[i])
+			var n int
+			if st := rt.READ(Star, []byte("*"), &n); st != 0 {
+				t.Errorf("Not valid iostat of READ: %d", st)
+			}
+			for k := 0; k < 3; k++ {
+				rt.WRITE(Star, []byte("(I3)"), n*k)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := range outs {
+		expect := fmt.Sprintf("%3d\n%3d\n%3d\n", 0, i, 2*i)
+		if o := outs[i].String(); o != expect {
+			t.Errorf("Not valid output of runtime %d: %q", i, o)
+		}
+	}
+
+	// statements of one runtime in many goroutines
+	rt := NewRuntime()
+	var out bytes.Buffer
+	_ = rt.AttachWriter(Star, &out)
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rt.WRITE(Star, []byte("(A,I2)"), []byte("line"), i)
+		}(i)
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != size {
+		t.Fatalf("Not valid amount of lines: %q", out.String())
+	}
+	for _, line := range lines {
+		if len(line) != 6 || !strings.HasPrefix(line, "line") {
+			t.Errorf("Not valid line: %q", line)
+		}
+	}
+}
+
+func TestRuntimeListCompression(t *testing.T) {
+	ListCompression = true
+	defer func() {
+		ListCompression = false
+	}()
+	for _, compress := range []bool{false, true} {
+		rt := NewRuntime()
+		rt.SetListCompression(compress)
+		var out bytes.Buffer
+		_ = rt.AttachWriter(Star, &out)
+		rt.WRITE(Star, []byte("*"), []int{1, 1, 1})
+		if o := strings.TrimSpace(out.String()); strings.Contains(o, "3*1") != compress {
+			t.Errorf("Not valid output with compression %v: %q", compress, o)
+		}
+	}
+}
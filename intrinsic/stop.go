@@ -0,0 +1,151 @@
+package intrinsic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Stop is termination of program by statements STOP and ERROR STOP
+type Stop struct {
+	// Code is exit status of program
+	Code int
+
+	// Message for standard error output.
+	// Empty message is not printed.
+	Message string
+}
+
+func (s Stop) Error() string {
+	if s.Message == "" {
+		return fmt.Sprintf("STOP with exit status %d", s.Code)
+	}
+	return s.Message
+}
+
+// StopHook is called for termination of program after message of
+// termination is written. Default hook terminates the process with
+// status code.
+//
+// Library code may replace the hook for intercept termination.
+// If hook returns, then STOP panics with value of type Stop, so
+// termination can be recovered by caller.
+var StopHook = func(s Stop) {
+	os.Exit(s.Code)
+}
+
+// stop writes message of termination to w and calls StopHook.
+// Empty message is not written.
+func stop(w io.Writer, s Stop) {
+	if s.Message != "" {
+		fmt.Fprintln(w, s.Message)
+	}
+	StopHook(s)
+	panic(s)
+}
+
+// runtimeError terminates program with error of Fortran runtime,
+// which is not status of input/output statement, for example error of
+// ALLOCATE or not valid type of item in generated code. Message is
+// written to standard error output, because error is not related to
+// any runtime.
+func runtimeError(format string, a ...interface{}) {
+	stop(os.Stderr, Stop{Code: 2,
+		Message: "Fortran runtime error: " + fmt.Sprintf(format, a...)})
+}
+
+// messages return unit 0 for messages of termination.
+// Standard error output is used, if unit 0 is not connected for
+// output. Caller holds lock of runtime.
+func (rt *Runtime) messages() io.Writer {
+	if u, ok := rt.units[0]; ok && u.check("WRITE", "FORMATTED") == nil {
+		return u
+	}
+	return os.Stderr
+}
+
+// stop writes message of termination to unit 0 and terminates program.
+// Caller holds lock of runtime.
+func (rt *Runtime) stop(s Stop) {
+	stop(rt.messages(), s)
+}
+
+// runtimeError terminates program with error of input/output
+// statement. Caller holds lock of runtime.
+func (rt *Runtime) runtimeError(format string, a ...interface{}) {
+	rt.stop(Stop{Code: 2,
+		Message: "Fortran runtime error: " + fmt.Sprintf(format, a...)})
+}
+
+// stopCode return integer code or character message of statement
+func stopCode(code []interface{}) (c int, msg string, isInt bool) {
+	if len(code) == 0 || code[0] == nil {
+		return
+	}
+	switch v := reflect.ValueOf(code[0]); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// INTEGER of any kind
+		return int(v.Int()), "", true
+	}
+	return 0, string(castToBytes(code[0])), false
+}
+
+// STOP terminates program. Message is written to unit 0.
+//  STOP      - exit status 0 without message
+//  STOP 3    - message `STOP 3`, exit status 3
+//  STOP 'OK' - message `STOP OK`, exit status 0
+func (rt *Runtime) STOP(code ...interface{}) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	c, msg, isInt := stopCode(code)
+	switch {
+	case isInt:
+		rt.stop(Stop{Code: c, Message: fmt.Sprintf("STOP %d", c)})
+	case len(code) > 0:
+		rt.stop(Stop{Code: 0, Message: "STOP " + msg})
+	default:
+		rt.stop(Stop{Code: 0})
+	}
+}
+
+// ERROR_STOP terminates program with error. Message is written to
+// unit 0.
+//  ERROR STOP      - message `ERROR STOP`, exit status 1
+//  ERROR STOP 3    - message `ERROR STOP 3`, exit status 3
+//  ERROR STOP 'NO' - message `ERROR STOP NO`, exit status 1
+func (rt *Runtime) ERROR_STOP(code ...interface{}) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	c, msg, isInt := stopCode(code)
+	if isInt {
+		rt.stop(Stop{Code: c, Message: fmt.Sprintf("ERROR STOP %d", c)})
+		return
+	}
+	rt.stop(Stop{Code: 1, Message: strings.TrimSpace("ERROR STOP " + msg)})
+}
+
+// PAUSE suspends execution of program until input `go` from unit 5.
+// Messages are written to unit 0. Other input terminates program.
+func (rt *Runtime) PAUSE(code ...interface{}) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	c, msg, isInt := stopCode(code)
+	if isInt {
+		msg = fmt.Sprintf("%d", c)
+	}
+	w := rt.messages()
+	fmt.Fprintln(w, strings.TrimSpace("PAUSE "+msg))
+	fmt.Fprintln(w, "To resume execution, type go.  "+
+		"Other input will terminate the job.")
+	var line []byte
+	if r, e := rt.input(5, map[string]interface{}{}); e == nil {
+		line, _ = nextLine(r)
+	}
+	if strings.TrimRight(string(line), "\r") != "go" {
+		rt.stop(Stop{Code: 0, Message: "STOP"})
+		return
+	}
+	fmt.Fprintln(w, "RESUMED")
+}
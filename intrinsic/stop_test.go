@@ -0,0 +1,146 @@
+package intrinsic
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// catchStop return termination of function intercepted by StopHook
+func catchStop(t *testing.T, f func()) (hook, panicked Stop) {
+	old := StopHook
+	StopHook = func(s Stop) {
+		hook = s
+	}
+	defer func() {
+		StopHook = old
+		r := recover()
+		s, ok := r.(Stop)
+		if !ok {
+			t.Fatalf("Not valid value of panic: %v", r)
+		}
+		panicked = s
+	}()
+	f()
+	return
+}
+
+func TestStop(t *testing.T) {
+	tcs := []struct {
+		name string
+		f    func()
+		code int
+		msg  string
+	}{
+		{"STOP", func() { STOP() }, 0, ""},
+		{"STOP int", func() { STOP(3) }, 3, "STOP 3"},
+		{"STOP int8", func() { STOP(int8(4)) }, 4, "STOP 4"},
+		{"STOP int16", func() { STOP(int16(5)) }, 5, "STOP 5"},
+		{"STOP int32", func() { STOP(int32(6)) }, 6, "STOP 6"},
+		{"STOP int64", func() { STOP(int64(7)) }, 7, "STOP 7"},
+		{"STOP character", func() { STOP([]byte("OK")) }, 0, "STOP OK"},
+		{"STOP byte", func() { STOP(byte('A')) }, 0, "STOP A"},
+		{"ERROR STOP", func() { ERROR_STOP() }, 1, "ERROR STOP"},
+		{"ERROR STOP int32", func() { ERROR_STOP(int32(3)) }, 3, "ERROR STOP 3"},
+		{"ERROR STOP character", func() { ERROR_STOP("NO") }, 1, "ERROR STOP NO"},
+	}
+	// messages of default runtime are not shown
+	_ = AttachWriter(0, &bytes.Buffer{})
+	defer func() {
+		_ = AttachWriter(0, os.Stderr)
+	}()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			hook, panicked := catchStop(t, tc.f)
+			if hook.Code != tc.code || hook.Message != tc.msg {
+				t.Errorf("Not valid hook value: %#v", hook)
+			}
+			if panicked != hook {
+				t.Errorf("Not same values of hook and panic: %#v %#v", hook, panicked)
+			}
+		})
+	}
+}
+
+func TestStopError(t *testing.T) {
+	tcs := []struct {
+		s   Stop
+		out string
+	}{
+		{Stop{Code: 2}, "STOP with exit status 2"},
+		{Stop{Code: 1, Message: "ERROR STOP"}, "ERROR STOP"},
+	}
+	for _, tc := range tcs {
+		if out := tc.s.Error(); out != tc.out {
+			t.Errorf("Not valid error for %#v: %s", tc.s, out)
+		}
+	}
+}
+
+// TestStopExit check exit status and standard error output of process
+// terminated by default hook.
+func TestStopExit(t *testing.T) {
+	if code := os.Getenv("F4GO_STOP"); code != "" {
+		if code == "ERROR" {
+			ERROR_STOP([]byte("NO"))
+		}
+		STOP(int32(len(code)))
+		return
+	}
+	tcs := []struct {
+		code   string
+		status int
+		stderr string
+	}{
+		{"XXX", 3, "STOP 3"},
+		{"ERROR", 1, "ERROR STOP NO"},
+	}
+	for _, tc := range tcs {
+		cmd := exec.Command(os.Args[0], "-test.run=TestStopExit")
+		cmd.Env = append(os.Environ(), "F4GO_STOP="+tc.code)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		e, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatalf("Not valid termination of process: %v", err)
+		}
+		if status := e.ExitCode(); status != tc.status {
+			t.Errorf("Not valid exit status for %s: %d", tc.code, status)
+		}
+		if out := strings.TrimSpace(stderr.String()); out != tc.stderr {
+			t.Errorf("Not valid output for %s: %q", tc.code, out)
+		}
+	}
+}
+
+func TestStopRuntime(t *testing.T) {
+	rt := NewRuntime()
+	var out bytes.Buffer
+	_ = rt.AttachWriter(0, &out)
+	_ = rt.AttachReader(5, strings.NewReader("go\nno\n"))
+
+	rt.PAUSE(1)
+	if s, _ := catchStop(t, func() { rt.PAUSE() }); s.Code != 0 || s.Message != "STOP" {
+		t.Errorf("Not valid termination of PAUSE: %#v", s)
+	}
+	if s, _ := catchStop(t, func() { rt.ERROR_STOP(int32(4)) }); s.Code != 4 {
+		t.Errorf("Not valid termination of ERROR STOP: %#v", s)
+	}
+	if s, _ := catchStop(t, func() { rt.WRITE(42, []byte("*"), 1) }); s.Code != 2 {
+		t.Errorf("Not valid termination of runtime error: %#v", s)
+	}
+	expect := "PAUSE 1\n" +
+		"To resume execution, type go.  Other input will terminate the job.\n" +
+		"RESUMED\n" +
+		"PAUSE\n" +
+		"To resume execution, type go.  Other input will terminate the job.\n" +
+		"STOP\n" +
+		"ERROR STOP 4\n" +
+		"Fortran runtime error: Unit 42 is not connected\n"
+	if o := out.String(); o != expect {
+		t.Errorf("Not valid messages: %q", o)
+	}
+}
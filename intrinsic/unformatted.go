@@ -0,0 +1,335 @@
+package intrinsic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// Unformatted sequential file of gfortran is sequence of records.
+// Each record is surrounded by markers with length of record.
+// Marker is 4 bytes integer in byte order of unit.
+// Long record is divided into subrecords. Leading marker is negative,
+// if record is continued in next subrecord. Trailing marker is
+// negative, if record is started in previous subrecord.
+
+// maxSubrecord is maximal length of subrecord in gfortran
+const maxSubrecord = 2147483639
+
+// nativeOrder is byte order of machine
+var nativeOrder binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 0 {
+		nativeOrder = binary.BigEndian
+	}
+}
+
+// convertOrder return byte order for value of specifier CONVERT
+func convertOrder(convert string) (order binary.ByteOrder, ok bool) {
+	switch convert {
+	case "NATIVE":
+		return nativeOrder, true
+	case "SWAP":
+		if nativeOrder == binary.LittleEndian {
+			return binary.BigEndian, true
+		}
+		return binary.LittleEndian, true
+	case "BIG_ENDIAN":
+		return binary.BigEndian, true
+	case "LITTLE_ENDIAN":
+		return binary.LittleEndian, true
+	}
+	return nil, false
+}
+
+// single is REAL or COMPLEX value of single precision in list of
+// unformatted input/output. Translator stores these values in types
+// float64 and complex128.
+type single struct {
+	value interface{}
+}
+
+// SINGLE return item of input/output list with single precision
+func SINGLE(value interface{}) interface{} {
+	return single{value: value}
+}
+
+// unformattedSize return size of element in record
+func unformattedSize(e reflect.Value, single bool) int {
+	switch e.Kind() {
+	case reflect.Slice:
+		// CHARACTER
+		return e.Len()
+	case reflect.Uint8, reflect.Int8:
+		return 1
+	case reflect.Int16:
+		return 2
+	case reflect.Bool, reflect.Int, reflect.Int32, reflect.Float32:
+		// default INTEGER and LOGICAL have 4 bytes
+		return 4
+	case reflect.Int64, reflect.Complex64:
+		return 8
+	case reflect.Float64:
+		if single {
+			return 4
+		}
+		return 8
+	case reflect.Complex128:
+		if single {
+			return 8
+		}
+		return 16
+	}
+	runtimeError("Not valid type for unformatted input/output: %v", e.Type())
+	return 0
+}
+
+func putFloat(b []byte, order binary.ByteOrder, v float64) {
+	if len(b) == 4 {
+		order.PutUint32(b, math.Float32bits(float32(v)))
+		return
+	}
+	order.PutUint64(b, math.Float64bits(v))
+}
+
+func getFloat(b []byte, order binary.ByteOrder) float64 {
+	if len(b) == 4 {
+		return float64(math.Float32frombits(order.Uint32(b)))
+	}
+	return math.Float64frombits(order.Uint64(b))
+}
+
+// putElement store element in bytes b with size of element
+func putElement(b []byte, order binary.ByteOrder, e reflect.Value) {
+	switch e.Kind() {
+	case reflect.Slice:
+		copy(b, e.Bytes())
+	case reflect.Uint8:
+		b[0] = byte(e.Uint())
+	case reflect.Int8:
+		b[0] = byte(e.Int())
+	case reflect.Int16:
+		order.PutUint16(b, uint16(e.Int()))
+	case reflect.Int, reflect.Int32:
+		order.PutUint32(b, uint32(e.Int()))
+	case reflect.Int64:
+		order.PutUint64(b, uint64(e.Int()))
+	case reflect.Bool:
+		var v uint32
+		if e.Bool() {
+			v = 1
+		}
+		order.PutUint32(b, v)
+	case reflect.Float32, reflect.Float64:
+		putFloat(b, order, e.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c, h := e.Complex(), len(b)/2
+		putFloat(b[:h], order, real(c))
+		putFloat(b[h:], order, imag(c))
+	}
+}
+
+// getElement set element from bytes b with size of element
+func getElement(b []byte, order binary.ByteOrder, e reflect.Value) {
+	switch e.Kind() {
+	case reflect.Slice:
+		copy(e.Bytes(), b)
+	case reflect.Uint8:
+		e.SetUint(uint64(b[0]))
+	case reflect.Int8:
+		e.SetInt(int64(int8(b[0])))
+	case reflect.Int16:
+		e.SetInt(int64(int16(order.Uint16(b))))
+	case reflect.Int, reflect.Int32:
+		e.SetInt(int64(int32(order.Uint32(b))))
+	case reflect.Int64:
+		e.SetInt(int64(order.Uint64(b)))
+	case reflect.Bool:
+		e.SetBool(order.Uint32(b) != 0)
+	case reflect.Float32, reflect.Float64:
+		e.SetFloat(getFloat(b, order))
+	case reflect.Complex64, reflect.Complex128:
+		h := len(b) / 2
+		e.SetComplex(complex(getFloat(b[:h], order), getFloat(b[h:], order)))
+	}
+}
+
+// unformattedUnit return connected unit for unformatted data transfer
+func (rt *Runtime) unformattedUnit(unit interface{}, action string) (*unitFile, *ioError) {
+	if _, ok := internalRecords(unit); ok {
+		return nil, newIOError(iostatOptionConflict,
+			"Unformatted data transfer on internal unit")
+	}
+	u, e := rt.connected(unit, action)
+	if e != nil {
+		return nil, e
+	}
+	if e := u.check(action, "UNFORMATTED"); e != nil {
+		return nil, e
+	}
+	return u, nil
+}
+
+// encodeItems return binary representation of items of list
+func encodeItems(order binary.ByteOrder, a []interface{}) (data []byte) {
+	for _, item := range a {
+		s, isSingle := item.(single)
+		if isSingle {
+			item = s.value
+		}
+		for _, e := range elements(item) {
+			size := unformattedSize(e, isSingle)
+			data = append(data, make([]byte, size)...)
+			putElement(data[len(data)-size:], order, e)
+		}
+	}
+	return
+}
+
+// decodeItems set items of list from binary representation.
+// Rest of data is ignored.
+func decodeItems(order binary.ByteOrder, data []byte, a []interface{}) *ioError {
+	for _, item := range a {
+		s, isSingle := item.(single)
+		if isSingle {
+			item = s.value
+		}
+		for _, e := range elements(item) {
+			size := unformattedSize(e, isSingle)
+			if len(data) < size {
+				return newIOError(iostatShortRecord,
+					"I/O past end of record on unformatted file")
+			}
+			getElement(data[:size], order, e)
+			data = data[size:]
+		}
+	}
+	return nil
+}
+
+// writeUnformatted write one record of unformatted file
+func (rt *Runtime) writeUnformatted(unit interface{}, sp map[string]interface{}, a []interface{}) *ioError {
+	u, e := rt.unformattedUnit(unit, "WRITE")
+	if e != nil {
+		return e
+	}
+	rec, e := u.record(sp)
+	if e != nil {
+		return e
+	}
+	data := encodeItems(u.order, a)
+	if rec > 0 {
+		return u.writeRecord(rec, data, 0)
+	}
+
+	var buf bytes.Buffer
+	marker := make([]byte, 4)
+	for first := true; first || len(data) > 0; first = false {
+		n := len(data)
+		if n > maxSubrecord {
+			n = maxSubrecord
+		}
+		head, tail := int32(n), int32(n)
+		if n < len(data) {
+			head = -head
+		}
+		if !first {
+			tail = -tail
+		}
+		u.order.PutUint32(marker, uint32(head))
+		buf.Write(marker)
+		buf.Write(data[:n])
+		u.order.PutUint32(marker, uint32(tail))
+		buf.Write(marker)
+		data = data[n:]
+	}
+	if _, err := u.Write(buf.Bytes()); err != nil {
+		return osError(err, "Cannot write to file '%s'", u.name)
+	}
+	return nil
+}
+
+// readUnformatted read one record of unformatted file.
+// Rest of record is skipped.
+func (rt *Runtime) readUnformatted(unit interface{}, sp map[string]interface{}, a []interface{}) *ioError {
+	u, e := rt.unformattedUnit(unit, "READ")
+	if e != nil {
+		return e
+	}
+	rec, e := u.record(sp)
+	if e != nil {
+		return e
+	}
+	if rec > 0 {
+		data, e := u.readRecord(rec, 0)
+		if e != nil {
+			return e
+		}
+		return decodeItems(u.order, data, a)
+	}
+
+	corrupted := newIOError(iostatCorruptFile,
+		"Unformatted file structure has been corrupted")
+
+	r := u.in()
+	var data []byte
+	marker := make([]byte, 4)
+	for first := true; ; first = false {
+		if _, err := io.ReadFull(r, marker); err != nil {
+			if err == io.EOF && first {
+				return newIOError(iostatEnd, "End of file")
+			}
+			return corrupted
+		}
+		head := int32(u.order.Uint32(marker))
+		n := head
+		if n < 0 {
+			n = -n
+		}
+		start := len(data)
+		data = append(data, make([]byte, n)...)
+		if _, err := io.ReadFull(r, data[start:]); err != nil {
+			return corrupted
+		}
+		if _, err := io.ReadFull(r, marker); err != nil {
+			return corrupted
+		}
+		if tail := int32(u.order.Uint32(marker)); tail != n && tail != -n {
+			return corrupted
+		}
+		if head >= 0 {
+			break
+		}
+	}
+
+	return decodeItems(u.order, data, a)
+}
+
+// backspaceUnformatted return position of preceding record of
+// unformatted sequential file
+func (u *unitFile) backspaceUnformatted(pos int64) (int64, error) {
+	marker := make([]byte, 4)
+	for pos > 0 {
+		if _, err := u.file.ReadAt(marker, pos-4); err != nil {
+			return 0, err
+		}
+		tail := int32(u.order.Uint32(marker))
+		n := tail
+		if n < 0 {
+			n = -n
+		}
+		pos -= int64(n) + 8
+		if tail >= 0 {
+			break
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos, nil
+}
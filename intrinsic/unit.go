@@ -0,0 +1,319 @@
+package intrinsic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"syscall"
+)
+
+// unitFile is state of connected external unit
+type unitFile struct {
+	name    string // name of file, empty for preconnected unit
+	file    unitStream
+	reader  *bufio.Reader    // buffered input from file
+	action  string           // READ, WRITE or READWRITE
+	access  string           // SEQUENTIAL or DIRECT
+	form    string           // FORMATTED or UNFORMATTED
+	recl    int              // length of record
+	order   binary.ByteOrder // byte order of unformatted data
+	nextrec int              // number of next record of direct access
+	scratch bool             // file is deleted after close
+	endfile bool             // position after endfile record
+}
+
+// unitStream is file of operating system or attached stream of unit
+type unitStream interface {
+	io.ReadWriteCloser
+	io.Seeker
+	io.ReaderAt
+	io.WriterAt
+	Truncate(size int64) error
+}
+
+// attachedStream is input or output of attached reader or writer.
+// Attached stream has no position, so it is sequential only.
+type attachedStream struct {
+	r io.Reader
+	w io.Writer
+}
+
+func (s attachedStream) Read(p []byte) (int, error) {
+	if s.r == nil {
+		return 0, syscall.EBADF
+	}
+	return s.r.Read(p)
+}
+
+func (s attachedStream) Write(p []byte) (int, error) {
+	if s.w == nil {
+		return 0, syscall.EBADF
+	}
+	return s.w.Write(p)
+}
+
+func (s attachedStream) Close() error                       { return nil }
+func (s attachedStream) Seek(int64, int) (int64, error)     { return 0, syscall.ESPIPE }
+func (s attachedStream) ReadAt([]byte, int64) (int, error)  { return 0, syscall.ESPIPE }
+func (s attachedStream) WriteAt([]byte, int64) (int, error) { return 0, syscall.ESPIPE }
+func (s attachedStream) Truncate(int64) error               { return syscall.ESPIPE }
+
+// in return buffered input of unit
+func (u *unitFile) in() *bufio.Reader {
+	if u.reader == nil {
+		u.reader = bufio.NewReader(u.file)
+	}
+	return u.reader
+}
+
+// tell return current position in file
+func (u *unitFile) tell() (int64, error) {
+	pos, err := u.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if u.reader != nil {
+		pos -= int64(u.reader.Buffered())
+	}
+	return pos, nil
+}
+
+// seek change position in file
+func (u *unitFile) seek(pos int64) error {
+	if _, err := u.file.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	if u.reader != nil {
+		u.reader.Reset(u.file)
+	}
+	return nil
+}
+
+// Write output to file at position after last input
+func (u *unitFile) Write(p []byte) (int, error) {
+	if u.reader != nil && u.reader.Buffered() > 0 {
+		pos, err := u.tell()
+		if err != nil {
+			return 0, err
+		}
+		if err := u.seek(pos); err != nil {
+			return 0, err
+		}
+	}
+	return u.file.Write(p)
+}
+
+// defaultRecl is default length of record of sequential file in gfortran
+const defaultRecl = 1073741824
+
+// check return error, if data transfer with action and form
+// is not allowed for unit
+func (u *unitFile) check(action, form string) *ioError {
+	if !strings.Contains(u.action, action) {
+		if action == "WRITE" {
+			return newIOError(iostatBadAction,
+				"Cannot write to file opened for %s", u.action)
+		}
+		return newIOError(iostatBadAction,
+			"Cannot read from file opened for %s", u.action)
+	}
+	if u.form != form {
+		if form == "FORMATTED" {
+			return newIOError(iostatOptionConflict,
+				"Format present for UNFORMATTED data transfer")
+		}
+		return newIOError(iostatOptionConflict,
+			"Missing format for FORMATTED data transfer")
+	}
+	return nil
+}
+
+// unitNumber return number of external unit
+func unitNumber(unit interface{}) (n int, ok bool) {
+	switch v := unit.(type) {
+	case int:
+		return v, true
+	case *int:
+		return *v, true
+	}
+	return
+}
+
+// connected return state of connected external unit for action READ
+// or WRITE of data transfer. Unit Star is unit 5 for input and unit 6
+// for output.
+func (rt *Runtime) connected(unit interface{}, action string) (*unitFile, *ioError) {
+	n, ok := unitNumber(unit)
+	if !ok {
+		return nil, newIOError(iostatBadUnit, "Not valid unit: %#v", unit)
+	}
+	switch {
+	case n == Star && action == "READ":
+		n = 5
+	case n == Star && action == "WRITE":
+		n = 6
+	}
+	u, ok := rt.units[n]
+	if !ok || u == nil {
+		return nil, newIOError(iostatBadUnit, "Unit %d is not connected", n)
+	}
+	return u, nil
+}
+
+// output return writer of unit for one statement WRITE with
+// specifiers sp. Function done must be called after end of statement.
+func (rt *Runtime) output(unit interface{}, sp map[string]interface{}) (
+	w io.Writer, done func() *ioError, e *ioError) {
+	if records, ok := internalRecords(unit); ok {
+		f := &internalFile{records: records}
+		return f, f.flush, nil
+	}
+	u, e := rt.connected(unit, "WRITE")
+	if e != nil {
+		return nil, nil, e
+	}
+	if e = u.check("WRITE", "FORMATTED"); e != nil {
+		return nil, nil, e
+	}
+	rec, e := u.record(sp)
+	if e != nil {
+		return nil, nil, e
+	}
+	if rec > 0 {
+		f := &directFile{u: u, rec: rec}
+		return f, f.flush, nil
+	}
+	return u, func() *ioError { return nil }, nil
+}
+
+// recordLength return length of record of output
+func recordLength(w io.Writer) int {
+	switch f := w.(type) {
+	case *unitFile:
+		return f.recl
+	case *directFile:
+		return f.u.recl
+	case *internalFile:
+		if len(f.records) > 0 {
+			return len(f.records[0])
+		}
+	}
+	return defaultRecl
+}
+
+// input return reader of unit for one statement READ with
+// specifiers sp.
+func (rt *Runtime) input(unit interface{}, sp map[string]interface{}) (*bufio.Reader, *ioError) {
+	if records, ok := internalRecords(unit); ok {
+		var buf bytes.Buffer
+		for _, r := range records {
+			buf.Write(r)
+			buf.WriteByte('\n')
+		}
+		return bufio.NewReader(&buf), nil
+	}
+	u, e := rt.connected(unit, "READ")
+	if e != nil {
+		return nil, e
+	}
+	if e = u.check("READ", "FORMATTED"); e != nil {
+		return nil, e
+	}
+	rec, e := u.record(sp)
+	if e != nil {
+		return nil, e
+	}
+	if rec > 0 {
+		return bufio.NewReader(&directReader{u: u, rec: rec}), nil
+	}
+	return u.in(), nil
+}
+
+// nextLine return next record of input without newline
+func nextLine(r *bufio.Reader) ([]byte, *ioError) {
+	line, err := r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		// last record without newline
+		err = nil
+	}
+	if err == io.EOF {
+		return nil, newIOError(iostatEnd, "End of file")
+	}
+	if e, ok := err.(*ioError); ok {
+		return nil, e
+	}
+	if err != nil {
+		return nil, osError(err, "Cannot read from file")
+	}
+	return bytes.TrimSuffix(line, []byte("\n")), nil
+}
+
+// internalRecords return records of internal file.
+// Internal file is CHARACTER variable, element of CHARACTER array
+// with one record or CHARACTER array with record per element.
+func internalRecords(unit interface{}) (records [][]byte, ok bool) {
+	switch v := unit.(type) {
+	case []byte:
+		return [][]byte{v}, true
+	case *[]byte:
+		return [][]byte{*v}, true
+	case [][]byte:
+		return v, true
+	case *[][]byte:
+		return *v, true
+	}
+	if _, ok := unitNumber(unit); ok {
+		return nil, false
+	}
+	// multidimensional CHARACTER array in array element order
+	v := reflect.ValueOf(unit)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !isArray(v) {
+		return nil, false
+	}
+	for _, e := range elements(unit) {
+		if e.Kind() != reflect.Slice {
+			return nil, false
+		}
+		records = append(records, e.Bytes())
+	}
+	return records, true
+}
+
+// internalFile is CHARACTER variable used as unit for output.
+// Length of record is length of variable.
+type internalFile struct {
+	records [][]byte
+	buf     bytes.Buffer
+}
+
+func (f *internalFile) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+// flush store output in records of internal file.
+// Each written record is filled by blanks.
+func (f *internalFile) flush() *ioError {
+	out := f.buf.Bytes()
+	out = bytes.TrimSuffix(out, []byte("\n"))
+	for i, line := range bytes.Split(out, []byte("\n")) {
+		if i >= len(f.records) {
+			return newIOError(iostatEnd, "End of file of internal unit")
+		}
+		record := f.records[i]
+		if len(record) < len(line) {
+			return newIOError(iostatEOR,
+				"End of record of internal unit with length %d", len(record))
+		}
+		n := copy(record, line)
+		for ; n < len(record); n++ {
+			record[n] = ' '
+		}
+	}
+	return nil
+}
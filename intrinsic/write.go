@@ -1,80 +1,100 @@
 package intrinsic
 
-import (
-	"bytes"
-	"fmt"
-	"os"
-	"reflect"
-)
+import "bytes"
 
-var units map[int]*os.File
-
-func init() {
-	units = map[int]*os.File{}
-	units[6] = os.Stdout
-}
-
-func REWIND(unit int) {
-	units[unit].Seek(0, 0)
+// WRITE is formatted, list-directed or unformatted output.
+// Format is text of format specification, `*` for list-directed
+// output or nil for unformatted output. Text of format specification
+// is CHARACTER value or CHARACTER array with concatenation of elements
+// in array element order. Result is status of statement same as
+// value of specifier IOSTAT.
+//
+// Fortran:
+//  WRITE(6, '(I5, 2X, F8.3)') N, X
+//  WRITE(6, FMT) N, X
+// Go code:
+//  intrinsic.WRITE(6, []byte("(I5, 2X, F8.3)"), (*N), (*X))
+//  intrinsic.WRITE(6, (*FMT), (*N), (*X))
+func (rt *Runtime) WRITE(unit interface{}, format interface{}, a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	unit, sp := controlList(unit)
+	if format == nil {
+		// unformatted output
+		return rt.status(sp, rt.writeUnformatted(unit, sp, a))
+	}
+	return rt.status(sp, rt.writeFormatted(unit, sp, format, a))
 }
 
-func WRITE(unit int, format []byte, a ...interface{}) {
-again:
-	for i := 1; i < len(a); i++ {
-		b1, ok1 := a[i].([]byte)
-		for j := i + 1; j < len(a); j++ {
-			b2, ok2 := a[j].([]byte)
-			if ok1 && ok2 {
-				b1 = append(b1, b2...)
-				a = append(a[:i], append([]interface{}{b1}, a[i+1:]...)...)
-				goto again
-			}
-		}
+// writeFormatted is formatted or list-directed output
+func (rt *Runtime) writeFormatted(unit interface{}, sp map[string]interface{},
+	fs interface{}, a []interface{}) *ioError {
+	text, e := formatSpecification(fs)
+	if e != nil {
+		return e
 	}
-
-	for i := range a {
-		if str, ok := a[i].([]byte); ok {
-			a[i] = string(str)
+	w, done, e := rt.output(unit, sp)
+	if e != nil {
+		return e
+	}
+	if string(text) == "*" {
+		if err := writeList(w, recordLength(w), a, rt.listCompression()); err != nil {
+			e = osError(err, "Cannot write to file")
+		}
+	} else {
+		var f *format
+		if f, e = cachedFormat(text); e == nil {
+			t := formatted{list: dataList(a), w: w}
+			e = t.run(f)
 		}
 	}
-
-	fmt.Fprintf(units[unit], string(format), a...)
-}
-
-func OPEN(unit int, file []byte) {
-	f, err := os.Open(string(file))
-	if err != nil {
-		panic(err)
+	if err := done(); e == nil {
+		e = err
 	}
-	units[unit] = f
+	return e
 }
 
-func CLOSE(unit int) {
-	delete(units, unit)
+// READ is formatted or unformatted input. Arguments are pointers.
+// Format is same as for WRITE. Result is same as for WRITE.
+func (rt *Runtime) READ(unit interface{}, format interface{}, a ...interface{}) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	unit, sp := controlList(unit)
+	if format == nil {
+		// unformatted input
+		return rt.status(sp, rt.readUnformatted(unit, sp, a))
+	}
+	return rt.status(sp, rt.readFormatted(unit, sp, format, a))
 }
 
-func READ(unit *int, format []byte, a ...interface{}) {
-
-	format = bytes.TrimSpace(bytes.ToLower(format))
-
-	// Change from %15.4f to %15f
-	for i := 1; i < 20; i++ {
-		for j := 1; j <= i; j++ {
-			format = bytes.Replace(format,
-				[]byte(fmt.Sprintf("%c%d.%df", '%', i, j)),
-				[]byte(fmt.Sprintf("%cf", '%')),
-				-1)
-		}
+// readFormatted is formatted or list-directed input
+func (rt *Runtime) readFormatted(unit interface{}, sp map[string]interface{},
+	fs interface{}, a []interface{}) *ioError {
+	text, e := formatSpecification(fs)
+	if e != nil {
+		return e
 	}
+	r, e := rt.input(unit, sp)
+	if e != nil {
+		return e
+	}
+	if string(text) == "*" {
+		return readList(r, a)
+	}
+	f, e := cachedFormat(text)
+	if e != nil {
+		return e
+	}
+	t := formatted{list: dataList(a), r: r}
+	return t.run(f)
+}
 
-	ft := string(format)
-	_, err := fmt.Fscanf(units[*unit], ft, a...)
-	if err != nil {
-		var types string
-		for i := range a {
-			types += fmt.Sprintf("|%s|", reflect.TypeOf(a[i]))
-		}
-		panic(fmt.Errorf("READ error for format `%s` : %v\nValues = %v",
-			ft, err, types))
+// formatSpecification return text of format specification.
+func formatSpecification(format interface{}) ([]byte, *ioError) {
+	records, ok := internalRecords(format)
+	if !ok {
+		return nil, newIOError(iostatFormat,
+			"Format is not CHARACTER value: %T", format)
 	}
+	return bytes.Join(records, nil), nil
 }
@@ -17,9 +17,14 @@ import (
 
 var packageFlag *string
 
+var ioFlag *string
+
 func main() {
 	packageFlag = flag.String("p",
 		"main", "set the name of the generated package")
+	ioFlag = flag.String("io",
+		"intrinsic", "set the Go expression of input/output runtime "+
+			"shared by all routines of the generated package")
 
 	run()
 }
@@ -39,6 +44,9 @@ func run() {
 		var s string
 		packageFlag = &s
 	}
+	if ioFlag != nil {
+		fortran.Runtime = *ioFlag
+	}
 
 	es := parseParallel(flag.Args(), *packageFlag)
 	for _, e := range es {
@@ -55,8 +63,9 @@ func (err errorRow) Error() string {
 	return fmt.Sprintf("%s : %v", err.filename, err.err)
 }
 
-// parsing to Go code
-func parse(filename, packageName, goFilename string) (errR []errorRow) {
+// parsing to Go code.
+// Sources of other files are used for find modules of USE statements.
+func parse(filename, packageName, goFilename string, modules ...[]byte) (errR []errorRow) {
 	if packageName == "" {
 		packageName = "main"
 	}
@@ -77,7 +86,7 @@ func parse(filename, packageName, goFilename string) (errR []errorRow) {
 	dat = bytes.Replace(dat, []byte{'\015'}, []byte{}, -1)
 
 	// parse fortran to go/ast
-	ast, errs := fortran.Parse(dat, packageName)
+	ast, errs := fortran.Parse(dat, packageName, modules...)
 	if len(errs) > 0 {
 		for _, er := range errs {
 			errR = append(errR, errorRow{
@@ -120,20 +129,30 @@ func parse(filename, packageName, goFilename string) (errR []errorRow) {
 
 func parseParallel(filenames []string, packageName string) (ess []errorRow) {
 	var (
-		jobs    = make(chan string, len(filenames))
+		jobs    = make(chan int, len(filenames))
 		results = make(chan []errorRow, len(filenames))
 	)
 
+	// sources for modules
+	sources := make([][]byte, len(filenames))
+	for i := range filenames {
+		sources[i], _ = ioutil.ReadFile(filenames[i])
+		sources[i] = bytes.Replace(sources[i], []byte{'\r'}, []byte{}, -1)
+	}
+
 	for w := 1; w <= 2*runtime.NumCPU(); w++ {
-		go func(jobs <-chan string, results chan<- []errorRow) {
+		go func(jobs <-chan int, results chan<- []errorRow) {
 			for job := range jobs {
-				results <- parse(job, packageName, "")
+				var modules [][]byte
+				modules = append(modules, sources[:job]...)
+				modules = append(modules, sources[job+1:]...)
+				results <- parse(filenames[job], packageName, "", modules...)
 			}
 		}(jobs, results)
 	}
 
-	for _, f := range filenames {
-		jobs <- f
+	for i := range filenames {
+		jobs <- i
 	}
 	close(jobs)
 
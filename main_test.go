@@ -559,3 +559,71 @@ func TestCrash(t *testing.T) {
 		t.Fatal("Not enougth code")
 	}
 }
+
+func TestRuntimeFlag(t *testing.T) {
+	dir := "./testdata/runtime"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := `
+      PROGRAM RUNTIME
+      INTEGER N
+      READ (*, *) N
+      WRITE (*, '(A,I3)') 'twice', N * 2
+      STOP 'done'
+      END
+`
+	fortranFile := filepath.Join(os.TempDir(), "f4go_runtime.f")
+	if err := ioutil.WriteFile(fortranFile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fortranFile)
+	// runtime of package
+	rt := `package main
+
+import (
+	"strings"
+
+	"github.com/Konstantin8105/f4go/intrinsic"
+)
+
+var RT = func() *intrinsic.Runtime {
+	rt := intrinsic.NewRuntime()
+	_ = rt.AttachReader(intrinsic.Star, strings.NewReader("21\n"))
+	return rt
+}()
+`
+	if err := ioutil.WriteFile(dir+"/rt.go", []byte(rt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fortran.Runtime = "RT"
+	defer func() {
+		fortran.Runtime = "intrinsic"
+	}()
+	if es := parse(fortranFile, "", dir+"/runtime.go"); len(es) > 0 {
+		t.Fatalf("Error is not empty: %v", es)
+	}
+	goSource, err := ioutil.ReadFile(dir + "/runtime.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"RT.READ(", "RT.WRITE(", "RT.STOP("} {
+		if !bytes.Contains(goSource, []byte(s)) {
+			t.Errorf("Cannot find `%s` in Go code:\n%s", s, goSource)
+		}
+	}
+	if bytes.Contains(goSource, []byte("intrinsic")) {
+		t.Errorf("Package intrinsic is used in Go code:\n%s", goSource)
+	}
+
+	out, err := exec.Command("go", "run", dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Cannot run Go code: %v\n%s", err, out)
+	}
+	if string(out) != "twice 42\nSTOP done\n" {
+		t.Errorf("Not valid output: %q", out)
+	}
+}
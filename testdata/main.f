@@ -2,6 +2,96 @@ C -----------------------------------------------------
 C Tests 
 C -----------------------------------------------------
 
+        MODULE test_shapes
+            IMPLICIT NONE
+            PRIVATE
+            PUBLIC :: PI, TWOPI, NSIDE, NCALL, SCALE, AREA
+            REAL*8, PARAMETER :: PI = 3.14159D0
+            REAL*8, PARAMETER :: TWOPI = 2 * PI
+            INTEGER NSIDE
+            PARAMETER (NSIDE = 3)
+            INTEGER :: NCALL = 0
+            REAL*8 SCALE, HIDDEN
+            REAL*8 TABLE(NSIDE)
+        CONTAINS
+            REAL*8 FUNCTION AREA(R)
+                REAL*8 R
+                CALL BUMP
+                AREA = PI * R * R * SCALE
+                RETURN
+            END FUNCTION AREA
+
+            SUBROUTINE BUMP
+                NCALL = NCALL + 1
+                HIDDEN = HIDDEN + 0.5D0
+                TABLE(NCALL) = HIDDEN
+            END SUBROUTINE BUMP
+        END MODULE test_shapes
+
+        MODULE test_generic
+            PRIVATE :: ISWAP, DSWAP
+            INTERFACE SWAPV
+                MODULE PROCEDURE ISWAP, DSWAP
+            END INTERFACE
+            INTERFACE NORM
+                MODULE PROCEDURE SNORM, DNORM
+            END INTERFACE
+            INTERFACE OPERATOR(.HYPOT.)
+                MODULE PROCEDURE HYPOT
+            END INTERFACE
+            INTERFACE OPERATOR(+)
+                MODULE PROCEDURE LADD
+            END INTERFACE
+        CONTAINS
+            SUBROUTINE ISWAP(A, B)
+                INTEGER A, B, T
+                T = A
+                A = B
+                B = T
+            END SUBROUTINE ISWAP
+
+            SUBROUTINE DSWAP(A, B)
+                REAL*8 A, B, T
+                T = A
+                A = B
+                B = T
+            END SUBROUTINE DSWAP
+
+            REAL*8 FUNCTION HYPOT(X, Y)
+                REAL*8 X, Y
+                HYPOT = SQRT(X*X + Y*Y)
+                RETURN
+            END FUNCTION HYPOT
+
+            LOGICAL FUNCTION LADD(P, Q)
+                LOGICAL P, Q
+                LADD = P .NEQV. Q
+                RETURN
+            END FUNCTION LADD
+
+            REAL FUNCTION SNORM(X)
+                REAL X
+                SNORM = ABS(X) + 1
+                RETURN
+            END FUNCTION SNORM
+
+            REAL*8 FUNCTION DNORM(X)
+                REAL*8 X
+                DNORM = ABS(X) + 2
+                RETURN
+            END FUNCTION DNORM
+        END MODULE test_generic
+
+        MODULE test_loops
+            INTEGER NLOOP
+            REAL*8, PRIVATE :: HIDDEN = 0
+        CONTAINS
+            SUBROUTINE GROW
+                NLOOP = NLOOP + 1
+                HIDDEN = HIDDEN + 1
+            END SUBROUTINE GROW
+        END MODULE test_loops
+
         program MAIN_PROGRAM
             ! begin of tests
             call testName("test_operations")
@@ -139,6 +229,76 @@ C           call test_common_satellite()
             call testName("test_epsilon")
             call test_epsilon()
 
+            call testName("test_alternate_return")
+            call test_alternate_return()
+
+            call testName("test_select_case")
+            call test_select_case()
+
+            call testName("test_do_trip")
+            call test_do_trip()
+
+            call testName("test_array_expression")
+            call test_array_expression()
+
+            call testName("test_allocatable")
+            call test_allocatable()
+
+            call testName("test_module")
+            call test_module()
+
+            call testName("test_internal")
+            call test_internal(5)
+
+            call testName("test_generic")
+            call test_generic_calls()
+
+            call testName("test_optional")
+            call test_optional()
+
+            call testName("test_recursive")
+            call test_recursive()
+
+            call testName("test_namelist")
+            call test_namelist()
+
+            call testName("test_internal_file")
+            call test_internal_file()
+
+            call testName("test_inquire")
+            call test_inquire()
+
+            call testName("test_open_close")
+            call test_open_close()
+
+            call testName("test_unformatted")
+            call test_unformatted()
+
+            call testName("test_direct")
+            call test_direct()
+
+            call testName("test_format")
+            call test_format()
+
+            call testName("test_list_output")
+            call test_list_output()
+
+            call testName("test_list_input")
+            call test_list_input()
+
+            call testName("test_edit_descriptors")
+            call test_edit_descriptors()
+
+            call testName("test_runtime_format")
+            call test_runtime_format()
+
+            call testName("test_io_status")
+            call test_io_status()
+
+            call testName("test_implicit_save")
+            call test_implicit_save()
+            call test_implicit_save()
+
             ! end of tests
         END
 
@@ -1339,3 +1499,686 @@ C -----------------------------------------------------
 C -----------------------------------------------------
 
 
+
+        SUBROUTINE test_alternate_return
+            INTEGER I
+            DO 10 I = 1, 3
+                CALL alternate_return(I, *20, *30)
+                WRITE(*,'(A,I2)') 'normal return   ', I
+                GO TO 10
+  20            WRITE(*,'(A,I2)') 'first return    ', I
+                GO TO 10
+  30            WRITE(*,'(A,I2)') 'second return   ', I
+  10        CONTINUE
+            CALL alternate_return(1, *40)
+            call fail("alternate return 1")
+  40        CONTINUE
+            CALL alternate_end(0, *50)
+            WRITE(*,'(A)') 'end return      '
+            CALL alternate_end(1, *50)
+            call fail("alternate return end")
+  50        CONTINUE
+        END
+
+        SUBROUTINE alternate_return(I, *, *)
+            INTEGER I
+            IF (I .EQ. 1) RETURN 1
+            IF (I .EQ. 2) RETURN 2
+            RETURN
+        END
+
+        SUBROUTINE alternate_end(I, *)
+            INTEGER I
+            IF (I .GT. 0) RETURN 1
+        END
+
+C -----------------------------------------------------
+
+        SUBROUTINE test_select_case
+            INTEGER I, K
+            LOGICAL L
+            CHARACTER*8 C
+            DO 10 I = -1, 8
+                SELECT CASE (I)
+                CASE (:0)
+                    K = 0
+                CASE (1, 3:5)
+                    K = 1
+                CASE (7:)
+                    K = 2
+                CASE DEFAULT
+                    K = 3
+                END SELECT
+                WRITE(*,'(I3,I2)') I, K
+  10        CONTINUE
+            L = .FALSE.
+            SELECT CASE (L)
+            CASE (.TRUE.)
+                call fail("select case logical")
+            CASE (.FALSE.)
+                WRITE(*,'(A)') 'logical false'
+            END SELECT
+            C = 'beta'
+            SELECT CASE (C)
+            CASE ('alpha')
+                call fail("select case character 1")
+            CASE ('beta    ', 'gamma')
+                WRITE(*,'(A)') 'character beta'
+            CASE DEFAULT
+                call fail("select case character 2")
+            END SELECT
+            SELECT CASE (C)
+            CASE ('a':'az')
+                call fail("select case character 3")
+            CASE ('b':'c')
+                WRITE(*,'(A)') 'character range'
+            END SELECT
+        END
+
+C -----------------------------------------------------
+
+        SUBROUTINE test_do_trip
+            USE test_loops
+            INTEGER I, J, N, K, A(3,3)
+            REAL*8 X, S
+            N = 3
+            K = 0
+            DO I = 1, N
+                N = N + 1
+                K = K + 1
+            ENDDO
+            WRITE(*,'(A,I3,I3,I3)') 'bound ', I, N, K
+            K = 0
+            DO I = 10, 1, -3
+                K = K + 1
+            END DO
+            WRITE(*,'(A,I3,I3)') 'step  ', I, K
+            DO 10 I = 1, 3
+            DO 10 J = 1, 3
+  10        A(I,J) = I*10 + J
+            WRITE(*,'(A,I3,I3,I3)') 'label ', A(1,1), A(2,3), A(3,2)
+            K = 0
+            outer: DO I = 1, 5
+                DO J = 1, 5
+                    IF (J .GT. I) CYCLE outer
+                    IF (I .EQ. 4) EXIT outer
+                    K = K + 1
+                END DO
+            END DO outer
+            WRITE(*,'(A,I3,I3)') 'named ', I, K
+            K = 0
+            DO
+                K = K + 1
+                SELECT CASE (K)
+                CASE (5)
+                    EXIT
+                CASE DEFAULT
+                    CYCLE
+                END SELECT
+                call fail("do exit select")
+            END DO
+            WRITE(*,'(A,I3)') 'exit  ', K
+            DO 20 WHILE (K .LT. 8)
+                K = K + 1
+  20        CONTINUE
+            WRITE(*,'(A,I3)') 'while ', K
+            NLOOP = 3
+            K = 0
+            DO I = 1, NLOOP
+                CALL GROW
+                K = K + 1
+            END DO
+            WRITE(*,'(A,I3,I3)') 'module', NLOOP, K
+            S = 0
+            DO X = 0.0D0, 1.0D0, 0.25D0
+                S = S + X
+            END DO
+            WRITE(*,'(A,F6.3)') 'real  ', S
+        END
+
+C -----------------------------------------------------
+
+        SUBROUTINE test_array_expression
+            INTEGER I, J, N
+            REAL*8 A(5), B(5), C(0:4), M(2,3)
+            N = 5
+            DO I = 1, N
+                B(I) = DBLE(I)
+            END DO
+            C = 10
+            A = B + C
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'whole   ', A(1), A(3), A(5)
+            A(2:N:2) = 0
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'stride  ', A(1), A(2), A(4)
+            A(2:N) = A(1:N-1)
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'overlap ', A(2), A(3), A(5)
+            A(N:1:-1) = B
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'reverse ', A(1), A(2), A(5)
+            A = SQRT(B * B) + 1
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'sqrt    ', A(1), A(2), A(5)
+            WHERE (B > 3) A = 0
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'where   ', A(3), A(4), A(5)
+            WHERE (B > 3)
+                A = 1
+            ELSEWHERE (B > 1)
+                A = 2
+            ELSEWHERE
+                A = 3
+            END WHERE
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'elsewh  ', A(1), A(2), A(5)
+            M = 0
+            M(2,:) = B(1:3)
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'section ', M(1,2), M(2,1), M(2,3)
+            FORALL (I = 1:2, J = 1:3, I /= J) M(I,J) = DBLE(I*10 + J)
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'forall  ', M(1,1), M(1,2), M(2,3)
+            FORALL (I = 2:5)
+                A(I) = A(I-1)
+            END FORALL
+            WRITE(*,'(A,F5.1,F5.1,F5.1)') 'fortemp ', A(1), A(2), A(5)
+            I = 42
+            FORALL (I = 1:3) A(I) = DBLE(I) + ABS(-I)
+            WRITE(*,'(A,I3,F5.1)') 'forhost ', I, A(3)
+        END
+
+        SUBROUTINE test_allocatable
+            INTEGER :: I, N
+            REAL*8, ALLOCATABLE :: A(:), M(:,:), B(:)
+            INTEGER, DIMENSION(:), ALLOCATABLE :: K
+            N = 3
+            IF (ALLOCATED(A)) call fail("allocated before")
+            ALLOCATE(A(N), M(0:N,2), STAT=I)
+            WRITE(*,'(A,I2,I2,I2)') 'stat   ', I, SIZE(M,1), SIZE(M)
+            ALLOCATE(K(-1:1))
+            A = 1
+            K = 5
+            K(0) = 2
+            M = 3
+            M(0,1) = 7
+            WRITE(*,'(A,F5.1,I3,I3,F5.1)') 'bounds ', A(3), K(-1), K(0),
+     &          M(0,1)
+            ALLOCATE(A(2), STAT=I)
+            IF (I .EQ. 0) call fail("allocate twice")
+            B = A * 2
+            WRITE(*,'(A,I3,F5.1)') 'realloc', SIZE(B), B(3)
+            B = M(:,2)
+            WRITE(*,'(A,I3,F5.1)') 'realloc', SIZE(B), B(4)
+            DEALLOCATE(A, B, K)
+            IF (ALLOCATED(A)) call fail("deallocate")
+            N = 0
+            ALLOCATE(K(N:1))
+            N = 5
+            I = 0
+            K(I) = 42
+            K(I+1) = 43
+            WRITE(*,'(A,I3,I3)') 'runtime', K(0), K(1)
+        END
+
+        SUBROUTINE test_module
+            USE test_shapes, ONLY: CIRCLE => AREA, NCALL, SCALE, NSIDE,
+     &          TWOPI
+            REAL*8 X
+            SCALE = 2.0D0
+            X = CIRCLE(1.0D0)
+            X = CIRCLE(2.0D0)
+            WRITE(*,'(A,F8.3,I3,F8.3)') 'module ', X, NCALL, TWOPI
+            call test_module_size(NSIDE)
+        END
+
+        SUBROUTINE test_module_size(N)
+            INTEGER N
+            WRITE(*,'(A,I3)') 'module size', N
+        END
+
+        SUBROUTINE test_internal(N)
+            INTEGER N, TOTAL, R
+            REAL*8 A(3)
+            TOTAL = 0
+            A(2) = 0
+            call ADD(N)
+            call ADD(2)
+            R = TWICE(3)
+            WRITE(*,'(A,I4,I4,F6.1)') 'internal', TOTAL, R, A(2)
+        CONTAINS
+            SUBROUTINE ADD(K)
+                INTEGER K
+                TOTAL = TOTAL + K
+                A(2) = A(2) + DBLE(N)
+            END SUBROUTINE ADD
+
+            INTEGER FUNCTION TWICE(K)
+                INTEGER K, TOTAL
+                TOTAL = 100
+                call ADD(K)
+                TWICE = 2*K + TOTAL
+                RETURN
+            END FUNCTION TWICE
+        END SUBROUTINE test_internal
+
+        SUBROUTINE test_generic_calls
+            USE test_generic
+            INTEGER I, J
+            REAL R
+            REAL*8 X, Y, H
+            LOGICAL L
+            I = 1
+            J = 2
+            X = 3.0D0
+            Y = 4.0D0
+            call SWAPV(I, J)
+            call SWAPV(X, Y)
+            H = 1.0D0 + (X .HYPOT. Y)
+            L = .TRUE. + .FALSE.
+            WRITE(*,'(A,I2,I2,F5.1,F5.1,F5.1)') 'generic', I, J, X, Y, H
+            IF (.NOT. L) call fail("operator")
+            R = -1.0
+            WRITE(*,'(A,3F5.1)') 'norm   ', NORM(R), NORM(X), NORM(2.0)
+        END
+
+        SUBROUTINE test_optional
+            REAL*8 R1, R2, R3
+            INTEGER K
+            K = 4
+            call opt_sum(1, R1)
+            call opt_sum(RES=R2, N=K, TOL=0.5D0)
+            call opt_sum(2, TOL=0.25D0, RES=R3)
+            K = opt_twice(K)
+            WRITE(*,'(A,F6.2,F6.2,F6.2,I4)') 'optional', R1, R2, R3, K
+        END
+
+        SUBROUTINE opt_sum(N, RES, TOL)
+            INTEGER, INTENT(IN) :: N
+            REAL*8, INTENT(OUT) :: RES
+            REAL*8, OPTIONAL, INTENT(IN) :: TOL
+            RES = DBLE(N)
+            IF (PRESENT(TOL)) RES = RES + TOL
+        END
+
+        INTEGER FUNCTION opt_twice(K)
+            INTEGER, INTENT(IN) :: K
+            opt_twice = 2 * K
+            RETURN
+        END
+
+        SUBROUTINE test_recursive
+            INTEGER F, I, T
+            REAL*8 A(3), B(3)
+            F = fact(5)
+            DO I = 1, 3
+                A(I) = DBLE(I)
+            END DO
+            B = sq_plus(A)
+            T = pure_twice(4)
+            WRITE(*,'(A,I5,F6.1,F6.1,F6.1,I4)') 'recursive', F,
+     &          B(1), B(2), B(3), T
+        END
+
+        RECURSIVE INTEGER FUNCTION fact(N) RESULT(R)
+            INTEGER, INTENT(IN) :: N
+            IF (N .LE. 1) THEN
+                R = 1
+            ELSE
+                R = fact(N - 1)
+                R = N * R
+            END IF
+            RETURN
+        END
+
+        ELEMENTAL REAL*8 FUNCTION sq_plus(X)
+            REAL*8, INTENT(IN) :: X
+            sq_plus = X * X + 1.0D0
+            RETURN
+        END
+
+        PURE FUNCTION pure_twice(K)
+            INTEGER, INTENT(IN) :: K
+            INTEGER pure_twice
+            pure_twice = 2 * K
+            RETURN
+        END
+
+        SUBROUTINE test_namelist
+            REAL*8 DT, ARR(4)
+            INTEGER NSTEPS, I, K
+            LOGICAL VERBOSE
+            NAMELIST /PARAMS/ DT, NSTEPS, ARR /FLAGS/ VERBOSE
+            DT = 0.25D0
+            NSTEPS = 10
+            ARR = 1.0D0
+            VERBOSE = .FALSE.
+            WRITE (*, NML = PARAMS)
+            OPEN(UNIT=3, FILE = "./testdata/namelist")
+            READ (3, NML = PARAMS)
+            READ (3, FLAGS)
+            CLOSE(3)
+            WRITE (*, NML = PARAMS)
+            WRITE (*, FLAGS)
+            K = 0
+            NSTEPS = 2
+            OPEN(UNIT=3, FILE = "./testdata/namelist")
+            DO I = 1, NSTEPS
+                K = K + 1
+                IF (K .EQ. 1) THEN
+                    READ (3, NML = PARAMS)
+                END IF
+            END DO
+            CLOSE(3)
+            WRITE (*, '(A,I4,I4)') 'trip', K, NSTEPS
+        END
+
+        SUBROUTINE test_internal_file
+            CHARACTER*10 STR
+            CHARACTER*20 LINE
+            CHARACTER*8 ROWS(3)
+            INTEGER N, I
+            REAL*8 X, Y
+            N = 42
+            WRITE (STR, '(I5)') N
+            WRITE (*, '(A,I3)') STR, 0
+            LINE = '12 3.5 7.25'
+            READ (LINE, '(I2,F4.1,F5.2)') N, X, Y
+            WRITE (*, '(I5,F8.3,F8.3)') N, X, Y
+            DO I = 1, 3
+                WRITE (ROWS(I), '(A,I3)') 'row', I
+            END DO
+            WRITE (*, '(A,I3)') ROWS(2), 0
+            WRITE (ROWS, '(I4/I4)') 7, 8
+            WRITE (*, '(A,I3)') ROWS(1), 1
+            WRITE (*, '(A,I3)') ROWS(2), 2
+        END
+
+        SUBROUTINE test_inquire
+            LOGICAL EX, OP
+            INTEGER N, NUM
+            CHARACTER*10 ACC
+            INQUIRE (FILE = "./testdata/text", EXIST = EX, OPENED = OP)
+            WRITE (*, '(A,L2,L2)') 'file  ', EX, OP
+            INQUIRE (FILE = "./testdata/none", EXIST = EX)
+            WRITE (*, '(A,L2)') 'none  ', EX
+            OPEN (UNIT = 4, FILE = "./testdata/text")
+            INQUIRE (4, OPENED = OP, NUMBER = NUM, ACCESS = ACC)
+            WRITE (*, '(A,L2,I3,A12)') 'unit  ', OP, NUM, ACC
+            READ (4, '(I7)') N
+            READ (4, '(I7)') N
+            BACKSPACE 4
+            READ (4, '(I7)') N
+            WRITE (*, '(A,I5)') 'back  ', N
+            BACKSPACE (4)
+            BACKSPACE (UNIT = 4)
+            READ (4, '(I7)') N
+            WRITE (*, '(A,I5)') 'back2 ', N
+            REWIND 4
+            READ (4, '(I7)') N
+            WRITE (*, '(A,I5)') 'rewind', N
+        END
+
+        SUBROUTINE test_open_close
+            INTEGER N, IOS
+            LOGICAL EX
+            CHARACTER*10 ACT
+            OPEN (UNIT = 7, FILE = "./testdata/open.tmp",
+     &            STATUS = 'REPLACE')
+            WRITE (7, '(I4)') 11
+            WRITE (7, '(I4)') 22
+            REWIND 7
+            READ (7, '(I4)') N
+            WRITE (*, '(A,I5)') 'replace', N
+            CLOSE (7)
+            OPEN (7, FILE = "./testdata/open.tmp", POSITION = 'APPEND',
+     &            ACTION = 'WRITE')
+            INQUIRE (7, ACTION = ACT)
+            WRITE (*, '(A,A7)') 'action ', ACT
+            WRITE (7, '(I4)') 33
+            CLOSE (7)
+            OPEN (8, FILE = "./testdata/open.tmp", STATUS = 'OLD')
+            READ (8, '(I4)') N
+            READ (8, '(I4)') N
+            READ (8, '(I4)') N
+            WRITE (*, '(A,I5)') 'append ', N
+            CLOSE (8, STATUS = 'DELETE')
+            INQUIRE (FILE = "./testdata/open.tmp", EXIST = EX)
+            WRITE (*, '(A,L2)') 'delete ', EX
+            OPEN (9, FILE = "./testdata/none", STATUS = 'OLD',
+     &            IOSTAT = IOS)
+            WRITE (*, '(A,I5)') 'iostat ', IOS
+            OPEN (9, FILE = "./testdata/none", STATUS = 'OLD', ERR = 10)
+            WRITE (*, '(A)') 'error is not found'
+   10       OPEN (10, STATUS = 'SCRATCH')
+            WRITE (10, '(I4)') 44
+            REWIND 10
+            READ (10, '(I4)') N
+            CLOSE (10)
+            WRITE (*, '(A,I5)') 'scratch', N
+        END
+
+        SUBROUTINE test_unformatted
+            INTEGER N, M(3), SIZE
+            REAL A(3), S
+            DOUBLE PRECISION D
+            COMPLEX C
+            CHARACTER*5 NAME
+            LOGICAL L
+            N = 7
+            S = 1.5
+            D = 2.25D0
+            C = (1.0, -2.0)
+            NAME = 'hello'
+            L = .TRUE.
+            A(1) = 0.5
+            A(2) = 1.0
+            A(3) = 1.5
+            M(1) = -1
+            M(2) = -2
+            M(3) = -3
+            OPEN (10, FILE = "./testdata/unformatted.tmp",
+     &            FORM = 'UNFORMATTED', STATUS = 'REPLACE')
+            WRITE (10) N, S, D
+            WRITE (10) A, M, C, NAME, L
+            INQUIRE (10, SIZE = SIZE)
+            WRITE (*, '(A,I5)') 'size  ', SIZE
+            REWIND 10
+            N = 0
+            S = 0
+            D = 0
+            READ (10) N, S, D
+            WRITE (*, '(A,I3,F8.3,F8.3)') 'scalar', N, S, D
+            READ (10) A, M, C, NAME, L
+            WRITE (*, '(A,F6.2,F6.2,F6.2)') 'array ', A(1), A(2), A(3)
+            WRITE (*, '(A,I3,I3,I3)') 'array ', M(1), M(2), M(3)
+            WRITE (*, '(A,F6.2,F6.2,A6,L2)') 'other ', REAL(C), AIMAG(C),
+     &            NAME, L
+            BACKSPACE 10
+            BACKSPACE 10
+            READ (10) N
+            WRITE (*, '(A,I3)') 'back  ', N
+            CLOSE (10, STATUS = 'DELETE')
+            OPEN (12, FILE = "./testdata/single.tmp",
+     &            FORM = 'UNFORMATTED', STATUS = 'REPLACE')
+            WRITE (12) S, S * 2.0, A(1) + 1
+            INQUIRE (12, SIZE = SIZE)
+            WRITE (*, '(A,I5)') 'single', SIZE
+            CLOSE (12, STATUS = 'DELETE')
+        END
+
+        SUBROUTINE test_direct
+            INTEGER N, K, NR
+            DOUBLE PRECISION D
+            OPEN (10, FILE = "./testdata/direct.tmp", ACCESS = 'DIRECT',
+     &            RECL = 12, STATUS = 'REPLACE')
+            D = 0
+            DO K = 1, 3
+                D = D + 1.5D0
+                WRITE (10, REC = K) K, D
+            END DO
+            READ (10, REC = 2) N, D
+            WRITE (*, '(A,I3,F8.3)') 'unformatted', N, D
+            INQUIRE (10, NEXTREC = NR)
+            WRITE (*, '(A,I3)') 'nextrec    ', NR
+            CLOSE (10, STATUS = 'DELETE')
+            OPEN (11, FILE = "./testdata/direct.tmp", ACCESS = 'DIRECT',
+     &            RECL = 8, FORM = 'FORMATTED', STATUS = 'REPLACE')
+            WRITE (11, '(I4)', REC = 3) 33
+            WRITE (11, '(I4)', REC = 1) 11
+            WRITE (11, FMT = '(I4/I4)', REC = 4) 44, 55
+            READ (11, '(I4)', REC = 5) N
+            WRITE (*, '(A,I3)') 'formatted  ', N
+            READ (11, '(I4)', REC = 1) N
+            WRITE (*, '(A,I3)') 'formatted  ', N
+            CLOSE (11, STATUS = 'DELETE')
+        END
+
+        SUBROUTINE test_format
+            INTEGER IA(6), N
+            DOUBLE PRECISION X, Y
+            CHARACTER*20 LINE
+            DO N = 1, 6
+                IA(N) = N * 11
+            END DO
+            WRITE (*, '(3(I3,1X))') IA
+            WRITE (*, 100) IA
+            WRITE (*, '(A, 2(I2, :, ", "))') 'colon ', 7
+            WRITE (*, '(I5.3, I3, I1)') 7, -12, 10
+            X = 12.3456D0
+            WRITE (*, '(F8.3, 1X, E12.4, 1X, D12.4)') X, X, X
+            WRITE (*, '(1P, E12.4, 1X, F8.3, 0P, E12.4)') X, X, X
+            WRITE (*, '(-1PE12.4, 0P, F6.2, F3.2)') X, -0.5D0, 0.5D0
+            LINE = ' 12 34,-1.5E1'
+            READ (LINE, '(I3, F4.2, F8.1)') N, X, Y
+            WRITE (*, '(I4, 2F10.4)') N, X, Y
+  100       FORMAT ('array', 2I3 / (1X, 2I4))
+        END
+
+        SUBROUTINE test_list_output
+            INTEGER I, IA(4)
+            REAL R
+            DOUBLE PRECISION D
+            LOGICAL L
+            CHARACTER*30 LINE
+            D = 2.5D0
+            L = .FALSE.
+            DO I = 1, 4
+                IA(I) = I
+            END DO
+            PRINT *, 'D =', D, L, 'end'
+            PRINT *, 'a', 'b', -42
+            WRITE (*, *) 1.0D-5, 1.0D20
+            R = 0.5
+            WRITE (*, *) R * 2.0, 1.0E-5, SQRT(R), R * D
+            PRINT *
+            PRINT 100, IA(4)
+            PRINT '(A,I2)', 'IA(3) =', IA(3)
+            OPEN (12, FILE = './testdata/list.tmp', RECL = 30,
+     &            STATUS = 'REPLACE')
+            WRITE (12, *) IA
+            REWIND 12
+            DO I = 1, 2
+                READ (12, '(A)') LINE
+                WRITE (*, '(A)') LINE
+            END DO
+            CLOSE (12, STATUS = 'DELETE')
+  100       FORMAT ('IA(4) =', I2)
+        END
+
+        SUBROUTINE test_list_input
+            INTEGER I, J, K, IA(5)
+            DOUBLE PRECISION D, E
+            LOGICAL L
+            CHARACTER*4 S, T
+            COMPLEX*16 Z
+            CHARACTER*40 LINE
+            CHARACTER*10 LINES(3)
+            I = -1
+            J = -1
+            K = -1
+            LINE = '7,,2*1.5D0 .true. ''ab'' word / 99'
+            READ (LINE, *) I, J, D, E, L, S, T, K
+            WRITE (*, '(2I3, 2F6.2, L2, 1X, A, A, I3)') I, J, D, E, L,
+     &            S, T, K
+            LINES(1) = '1 2'
+            LINES(2) = '3*4 (1.5,'
+            LINES(3) = ' -2.0) 5'
+            READ (LINES, *) IA, Z
+            WRITE (*, '(5I2, 2F6.2)') IA, Z
+        END
+
+        SUBROUTINE test_edit_descriptors
+            INTEGER I, J, K
+            DOUBLE PRECISION A, B
+            CHARACTER*20 LINE
+            A = 1.5D0
+            WRITE (*, '(A,T10,A,TL6,A,TR2,A)') 'ab', 'cdefg', 'XY', 'z'
+            WRITE (*, '(SP,I5,F8.2,SS,I5,S,F8.2)') 12, A, 12, A
+            WRITE (*, '(1P,E12.4,0P,E12.4,E14.4E3)') A, A, A
+            WRITE (*, '(1PD12.4)') 12345.678D0
+            WRITE (*, '(3G12.4)') A, 1234.5678D0, 0.0D0
+            WRITE (*, '(G12.4,G12.4E3,G12.4)') 0.01D0, 1.0D10, 0.5D0
+            WRITE (*, '(G5.2,G4.1,G8.0)') 7, .TRUE., 'abc'
+            WRITE (*, '(B10,O6,Z6,Z10.8,B8.8)') 10, 64, 255, -1, 5
+            LINE = '  1 2  3.5 1 '
+            READ (LINE, '(BZ,I4,BN,I4,F5.1)') I, J, B
+            WRITE (*, '(2I4,F6.2)') I, J, B
+            LINE = '1011 FF 17'
+            READ (LINE, '(B4,1X,Z2,1X,O2)') I, J, K
+            WRITE (*, '(3I4)') I, J, K
+        END
+
+        SUBROUTINE test_runtime_format
+            INTEGER N
+            DOUBLE PRECISION A(3)
+            CHARACTER*20 FMT
+            CHARACTER*1 NCOL
+            CHARACTER*6 PARTS(3)
+            A(1) = 1.0D0
+            A(2) = 2.5D0
+            A(3) = -3.0D0
+            NCOL = '3'
+            FMT = '(' // NCOL // 'F10.3)'
+            WRITE (6, FMT) A
+            WRITE (6, FMT = FMT) A
+            WRITE (6, '(' // NCOL // 'F8.2)') A
+            PARTS(1) = '(I5,'
+            PARTS(2) = '2X,'
+            PARTS(3) = 'I3)'
+            WRITE (6, PARTS) 1, 2
+            FMT = '(I4)'
+            READ ('  42', FMT) N
+            PRINT FMT, N
+        END
+
+        SUBROUTINE test_io_status
+            INTEGER N, IOS, S
+            CHARACTER*32 MSG
+            OPEN (10, FILE = './testdata/status.tmp', STATUS = 'REPLACE')
+            WRITE (10, '(I3)') 1, 2, 3
+            REWIND (10, IOSTAT = IOS)
+            S = 0
+   10       READ (10, *, END = 20) N
+            S = S + N
+            GOTO 10
+   20       WRITE (*, '(A,I3)') 'sum', S
+            READ (10, *, IOSTAT = IOS) N
+            WRITE (*, '(A,I3)') 'eof', IOS
+            REWIND 10
+            READ (10, '(I3)', ERR = 30, END = 40) N
+            READ (10, '(I3)', ERR = 30, END = 40) N
+            READ (10, '(I3)', ERR = 30, END = 40) N
+            READ (10, '(I3)', ERR = 30, END = 40) N
+   30       WRITE (*, '(A)') 'err'
+   40       WRITE (*, '(A,I3)') 'end', N
+            CLOSE (10, STATUS = 'DELETE')
+            READ ('abc', '(I3)', IOSTAT = IOS, IOMSG = MSG) N
+            WRITE (*, '(I5,1X,A)') IOS, MSG
+            OPEN (11, FILE = './testdata/none.tmp', STATUS = 'OLD',
+     &            IOSTAT = IOS)
+            WRITE (*, '(I5)') IOS
+            BACKSPACE (12, ERR = 50)
+            WRITE (*, '(A)') 'no'
+   50       WRITE (*, '(A)') 'backspace err'
+        END
+
+        SUBROUTINE test_implicit_save
+            INTEGER :: NCALL = 0
+            REAL*8 :: V(2) = 1.5D0
+            NCALL = NCALL + 1
+            V(NCALL) = 0
+            WRITE (*, '(A,I3,F5.1,F5.1)') 'save', NCALL, V
+        END